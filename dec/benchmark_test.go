@@ -84,6 +84,38 @@ func Benchmark_Dec_StringScan(b *testing.B) {
 	})
 }
 
+func Benchmark_Dec_AppendString(b *testing.B) {
+	var buf []byte
+	doBenchmarkDec1(b, func(x *Dec) {
+		buf = x.AppendString(buf[:0])
+	})
+}
+
+func Benchmark_Dec_StringBytesScan(b *testing.B) {
+	var buf []byte
+	d := new(Dec)
+	doBenchmarkDec1(b, func(x *Dec) {
+		buf = x.AppendString(buf[:0])
+		d.SetStringBytes(buf)
+	})
+}
+
+func Benchmark_Dec_PgNumericEncode(b *testing.B) {
+	var buf []byte
+	doBenchmarkDec1(b, func(x *Dec) {
+		buf, _ = x.AppendPgNumeric(buf[:0])
+	})
+}
+
+func Benchmark_Dec_PgNumericEnDecode(b *testing.B) {
+	var buf []byte
+	d := new(Dec)
+	doBenchmarkDec1(b, func(x *Dec) {
+		buf, _ = x.AppendPgNumeric(buf[:0])
+		d.SetPgNumeric(buf)
+	})
+}
+
 func Benchmark_Dec_GobEncode(b *testing.B) {
 	doBenchmarkDec1(b, func(x *Dec) {
 		x.GobEncode()
@@ -152,6 +184,18 @@ func Benchmark_Dec_Quo_Fixed_HalfUp(b *testing.B) {
 	})
 }
 
+func Benchmark_Dec_Sqrt_Fixed_HalfEven(b *testing.B) {
+	doBenchmarkDec1(b, func(x *Dec) {
+		_ = new(Dec).Sqrt(x, Scale(maxscale), RoundHalfEven)
+	})
+}
+
+func Benchmark_Dec_Ln_Fixed_HalfEven(b *testing.B) {
+	doBenchmarkDec1(b, func(x *Dec) {
+		_ = new(Dec).Ln(x, Scale(maxscale), RoundHalfEven)
+	})
+}
+
 func Benchmark_Int_String(b *testing.B) {
 	doBenchmarkInt1(b, func(x *big.Int) {
 		x.String()
@@ -0,0 +1,572 @@
+package dec
+
+// This file implements arithmetic under a Context, in the manner of the
+// General Decimal Arithmetic specification.
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// A Condition is a set of the exceptional conditions that an operation under
+// a Context can raise.
+type Condition uint32
+
+const (
+	// Inexact is raised when a result is rounded and digits that are not
+	// zero are discarded.
+	Inexact Condition = 1 << iota
+	// Overflow is raised when the magnitude of a result is 10**MaxExponent
+	// or more. The result is an infinity with the sign of the exact result.
+	Overflow
+	// DivisionByZero is raised when a finite number other than zero is
+	// divided by zero. The result is an infinity.
+	DivisionByZero
+	// InvalidOperation is raised when an operation on operands that are not
+	// NaN has no defined result, such as Sqrt(-1), 0/0 or Inf-Inf. The
+	// result is NaN.
+	InvalidOperation
+	// Underflow is raised with Inexact when the magnitude of a result of
+	// Exp or Pow is too small to compute, less than 10**-6000. The result
+	// is 0 with a scale of the precision.
+	Underflow
+)
+
+var conditionNames = []string{"inexact", "overflow", "division by zero", "invalid operation", "underflow"}
+
+func (c Condition) String() string {
+	var names []string
+	for i, name := range conditionNames {
+		if c&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// ConditionError is the error of a Context when an operation raises a
+// condition that is trapped
+type ConditionError struct {
+	Op        string
+	Condition Condition
+}
+
+func (e ConditionError) Error() string {
+	return fmt.Sprintf("Context.%s: %s", e.Op, e.Condition)
+}
+
+// DefaultPrecision is the precision of the operations whose exact result may
+// have infinitely many digits, such as Quo, under a Context with a Precision
+// of zero. It is the precision of the IEEE 754 decimal128 format.
+const DefaultPrecision = 34
+
+// A Context runs operations on Dec values under one policy: results are
+// rounded to Precision significant digits with Rounder, and the conditions
+// raised are collected in Flags instead of causing a panic.
+//
+// The methods of Context mirror those of Dec, with the result as the first
+// argument, and return it to allow chaining. If an operation raises a
+// condition in Traps, the result is set to NaN and the first such condition
+// is returned by Err, so that a whole calculation can be checked once at the
+// end.
+//
+// A Context must not be used by multiple goroutines at once.
+type Context struct {
+	// Precision is the maximum number of significant digits of a result.
+	// Zero means that Add, Sub, Mul, Neg, Abs and Round are exact and that
+	// the other operations use DefaultPrecision.
+	Precision int
+	// MaxExponent, if not zero, is the exponent of the smallest power of
+	// ten that overflows. Exp and Pow overflow beyond 10**6000 in any case,
+	// as Dec does not compute their larger results.
+	MaxExponent int
+	// Rounder rounds the results; RoundHalfEven is used if it is nil. A
+	// result that RoundExact fails to round is NaN and raises Inexact.
+	Rounder Rounder
+	// Traps is the set of conditions that are errors.
+	Traps Condition
+	// Flags is the set of conditions raised since it was last cleared.
+	Flags Condition
+
+	err error
+}
+
+// Err returns the ConditionError of the first trapped condition raised since
+// the flags were last cleared, or nil.
+func (c *Context) Err() error {
+	return c.err
+}
+
+// ClearFlags clears Flags and the error returned by Err.
+func (c *Context) ClearFlags() {
+	c.Flags = 0
+	c.err = nil
+}
+
+func (c *Context) rounder() Rounder {
+	if c.Rounder == nil {
+		return RoundHalfEven
+	}
+	return c.Rounder
+}
+
+func (c *Context) precision() int {
+	if c.Precision <= 0 {
+		return DefaultPrecision
+	}
+	return c.Precision
+}
+
+// raise adds cond to the flags, and sets z to NaN and records the error if
+// it is trapped
+func (c *Context) raise(z *Dec, op string, cond Condition) *Dec {
+	c.Flags |= cond
+	if trapped := cond & c.Traps; trapped != 0 {
+		if c.err == nil {
+			c.err = ConditionError{Op: op, Condition: trapped}
+		}
+		return z.SetNaN()
+	}
+	return z
+}
+
+// flagRounder records whether the rounding of its Rounder discarded digits
+// that are not zero
+type flagRounder struct {
+	rounder Rounder
+	inexact bool
+}
+
+func (r *flagRounder) UseRemainder() bool {
+	return true
+}
+
+func (r *flagRounder) Round(z, quo *Dec, remNum, remDen *big.Int) *Dec {
+	if remNum.Sign() != 0 {
+		r.inexact = true
+	}
+	if !r.rounder.UseRemainder() {
+		return r.rounder.Round(z, quo, nil, nil)
+	}
+	return r.rounder.Round(z, quo, remNum, remDen)
+}
+
+// finish sets z to the result r of op from operands that are not NaN if
+// nanOperand is false, rounds it to the precision p and raises the
+// conditions
+func (c *Context) finish(z *Dec, op string, r *Dec, p int, inexact, nanOperand bool) *Dec {
+	var cond Condition
+	if r == nil {
+		// RoundExact
+		z.SetNaN()
+		cond |= Inexact
+	} else if r.IsNaN() {
+		z.SetNaN()
+		if !nanOperand {
+			cond |= InvalidOperation
+		}
+	} else if !r.isSpecial() {
+		if p > 0 && digits(r) > p {
+			fr := &flagRounder{rounder: c.rounder()}
+			if r = r.Round(r, r.Scale()-Scale(digits(r)-p), fr); r == nil {
+				return c.raise(z.SetNaN(), op, Inexact)
+			}
+			r = stripCarry(r, p)
+			inexact = inexact || fr.inexact
+		}
+		if inexact {
+			cond |= Inexact
+		}
+		if c.MaxExponent != 0 && r.Sign() != 0 && decExponent(r) > int64(c.MaxExponent) {
+			r.SetInf(r.Sign() < 0)
+			cond |= Overflow | Inexact
+		}
+		z.Set(r)
+	} else {
+		z.Set(r)
+	}
+	if cond != 0 {
+		return c.raise(z, op, cond)
+	}
+	return z
+}
+
+// digits returns the number of digits of the unscaled value of x
+func digits(x *Dec) int {
+	return int(decExponent(x) + int64(x.Scale()))
+}
+
+// stripCarry removes the extra zero of a result with p+1 digits that was
+// rounded up to a power of ten
+func stripCarry(x *Dec, p int) *Dec {
+	if digits(x) > p {
+		x.Unscaled().Quo(x.Unscaled(), bigInt[10])
+		x.SetScale(x.Scale() - 1)
+	}
+	return x
+}
+
+// trim removes trailing zeros of an exact result down to the ideal scale
+func trim(x *Dec, ideal Scale) *Dec {
+	if x.isSpecial() {
+		return x
+	}
+	u := x.Unscaled()
+	q, r := new(big.Int), new(big.Int)
+	for x.Scale() > ideal && u.Sign() != 0 {
+		if q.QuoRem(u, bigInt[10], r); r.Sign() != 0 {
+			break
+		}
+		u.Set(q)
+		x.SetScale(x.Scale() - 1)
+	}
+	if u.Sign() == 0 && x.Scale() > ideal {
+		x.SetScale(ideal)
+	}
+	return x
+}
+
+// fit returns the result of f rounded to p significant digits and whether
+// it is inexact, where f(s, r) computes the result rounded to scale s using
+// r, and the magnitude of the result is about 10**e
+func (c *Context) fit(p int, e int64, f func(s Scale, r Rounder) *Dec) (*Dec, bool) {
+	var z *Dec
+	var fr *flagRounder
+	for i := 0; i < 4; i++ {
+		fr = &flagRounder{rounder: c.rounder()}
+		z = f(Scale(int64(p)-e), fr)
+		if z == nil || z.isSpecial() {
+			break
+		}
+		if z.Sign() == 0 {
+			if !fr.inexact {
+				break
+			}
+			e -= int64(p)
+			continue
+		}
+		n := digits(z)
+		if n == p+1 && isPowerOf10(z, p) {
+			return stripCarry(z, p), fr.inexact
+		}
+		if n == p && fr.inexact && isPowerOf10(z, p-1) {
+			// the result may have been rounded up to a power of ten from
+			// a smaller exponent, with one more digit to round
+			lower := &flagRounder{rounder: c.rounder()}
+			if zz := f(Scale(int64(p)-e+1), lower); zz != nil && digits(zz) == p {
+				return zz, lower.inexact
+			}
+		}
+		if n == p {
+			return z, fr.inexact
+		}
+		e = decExponent(z)
+	}
+	return z, fr.inexact
+}
+
+// isPowerOf10 reports if the unscaled value of x is ±10**n
+func isPowerOf10(x *Dec, n int) bool {
+	return new(big.Int).Abs(x.Unscaled()).Cmp(exp10(Scale(n))) == 0
+}
+
+// Add sets z to the sum x+y rounded to the precision of c and returns z.
+func (c *Context) Add(z, x, y *Dec) *Dec {
+	return c.finish(z, "Add", new(Dec).Add(x, y), c.Precision, false, x.IsNaN() || y.IsNaN())
+}
+
+// Sub sets z to the difference x-y rounded to the precision of c and
+// returns z.
+func (c *Context) Sub(z, x, y *Dec) *Dec {
+	return c.finish(z, "Sub", new(Dec).Sub(x, y), c.Precision, false, x.IsNaN() || y.IsNaN())
+}
+
+// Mul sets z to the product x*y rounded to the precision of c and returns z.
+func (c *Context) Mul(z, x, y *Dec) *Dec {
+	s := int64(x.Scale()) + int64(y.Scale())
+	if s != int64(Scale(s)) && !x.isSpecial() && !y.isSpecial() && x.Sign()*y.Sign() != 0 {
+		if s < 0 {
+			return c.raise(z.SetInf(x.Sign() != y.Sign()), "Mul", Overflow|Inexact)
+		}
+		return c.raise(z.SetUnscaled(bigInt[0]).SetScale(Scale(c.precision())), "Mul", Inexact)
+	}
+	return c.finish(z, "Mul", new(Dec).Mul(x, y), c.Precision, false, x.IsNaN() || y.IsNaN())
+}
+
+// Neg sets z to -x rounded to the precision of c and returns z.
+func (c *Context) Neg(z, x *Dec) *Dec {
+	return c.finish(z, "Neg", new(Dec).Neg(x), c.Precision, false, x.IsNaN())
+}
+
+// Abs sets z to |x| rounded to the precision of c and returns z.
+func (c *Context) Abs(z, x *Dec) *Dec {
+	return c.finish(z, "Abs", new(Dec).Abs(x), c.Precision, false, x.IsNaN())
+}
+
+// Round sets z to x rounded to scale s using the rounder of c, and then to
+// the precision of c, and returns z.
+func (c *Context) Round(z, x *Dec, s Scale) *Dec {
+	fr := &flagRounder{rounder: c.rounder()}
+	r := new(Dec).Round(x, s, fr)
+	return c.finish(z, "Round", r, c.Precision, fr.inexact, x.IsNaN())
+}
+
+// Quo sets z to the quotient x/y and returns z. An exact quotient with no
+// more digits than the precision has the smallest scale that is at least
+// that of x less that of y, other quotients are rounded to the precision.
+// Dividing by zero raises DivisionByZero, or InvalidOperation for 0/0.
+func (c *Context) Quo(z, x, y *Dec) *Dec {
+	nan := x.IsNaN() || y.IsNaN()
+	if x.isSpecial() || y.isSpecial() {
+		return c.finish(z, "Quo", new(Dec).Quo(x, y, x.Scale()-y.Scale(), RoundDown), 0, false, nan)
+	}
+	if y.Sign() == 0 {
+		return c.quoByZero(z, "Quo", x)
+	}
+	p := c.precision()
+	if x.Sign() == 0 {
+		return c.finish(z, "Quo", NewDec(bigInt[0], x.Scale()-y.Scale()), p, false, false)
+	}
+	if q := new(Dec).QuoExact(x, y); q != nil && digits(q) <= p {
+		return c.finish(z, "Quo", q, p, false, false)
+	}
+	r, inexact := c.fit(p, decExponent(x)-decExponent(y)+1, func(s Scale, r Rounder) *Dec {
+		return new(Dec).Quo(x, y, s, r)
+	})
+	return c.finish(z, "Quo", r, p, inexact, false)
+}
+
+// quoByZero sets z to the quotient of a finite x by zero
+func (c *Context) quoByZero(z *Dec, op string, x *Dec) *Dec {
+	if x.Sign() == 0 {
+		return c.raise(z.SetNaN(), op, InvalidOperation)
+	}
+	return c.raise(z.SetInf(x.Sign() < 0), op, DivisionByZero)
+}
+
+// QuoInt sets z to the quotient x/y truncated towards zero and returns z.
+// Dividing by zero raises DivisionByZero, or InvalidOperation for 0/0, and
+// a quotient with more digits than a Precision that is not zero raises
+// InvalidOperation.
+func (c *Context) QuoInt(z, x, y *Dec) *Dec {
+	if c.quoIntInvalid(z, x, y, nil) {
+		return z
+	}
+	return c.finish(z, "QuoInt", new(Dec).QuoInt(x, y), 0, false, x.IsNaN() || y.IsNaN())
+}
+
+// Rem sets z to the remainder x - y*QuoInt(x, y) rounded to the precision of
+// c and returns z. The remainder by zero, or when the quotient has more
+// digits than a Precision that is not zero, raises InvalidOperation.
+func (c *Context) Rem(z, x, y *Dec) *Dec {
+	return c.rem(z, "Rem", x, y, (*Dec).Rem)
+}
+
+// Mod sets z to the modulus x - y*floor(x/y) rounded to the precision of c
+// and returns z. The modulus by zero, or when the quotient has more digits
+// than a Precision that is not zero, raises InvalidOperation.
+func (c *Context) Mod(z, x, y *Dec) *Dec {
+	return c.rem(z, "Mod", x, y, (*Dec).Mod)
+}
+
+func (c *Context) rem(z *Dec, op string, x, y *Dec, f func(z, x, y *Dec) *Dec) *Dec {
+	nan := x.IsNaN() || y.IsNaN()
+	switch {
+	case x.isSpecial() || y.isSpecial():
+	case y.Sign() == 0:
+		return c.raise(z.SetNaN(), op, InvalidOperation)
+	case c.quoIntTooLong(x, y):
+		return c.raise(z.SetNaN(), op, InvalidOperation)
+	}
+	return c.finish(z, op, f(new(Dec), x, y), c.Precision, false, nan)
+}
+
+// DivMod sets z to the quotient floor(x/y) and m, if it is not nil, to the
+// modulus x - y*floor(x/y) as Mod, and returns the pair (z, m). Unlike
+// QuoInt, the quotient is rounded towards negative infinity. The conditions
+// raised are those of QuoInt and Mod.
+func (c *Context) DivMod(z, x, y, m *Dec) (*Dec, *Dec) {
+	if c.quoIntInvalid(z, x, y, m) {
+		return z, m
+	}
+	nan := x.IsNaN() || y.IsNaN()
+	q, r := new(Dec).DivMod(x, y, new(Dec))
+	if m != nil {
+		c.finish(m, "Mod", r, c.Precision, false, nan)
+	}
+	return c.finish(z, "QuoInt", q, 0, false, nan), m
+}
+
+// quoIntInvalid reports if the integer division of x by y is by zero or
+// has a quotient with more digits than the precision, and if so sets z,
+// and m if it is not nil, as QuoInt and Mod do
+func (c *Context) quoIntInvalid(z, x, y, m *Dec) bool {
+	if x.isSpecial() || y.isSpecial() {
+		return false
+	}
+	if y.Sign() == 0 {
+		if m != nil {
+			c.raise(m.SetNaN(), "Mod", InvalidOperation)
+		}
+		c.quoByZero(z, "QuoInt", x)
+		return true
+	}
+	if c.quoIntTooLong(x, y) {
+		if m != nil {
+			c.raise(m.SetNaN(), "Mod", InvalidOperation)
+		}
+		c.raise(z.SetNaN(), "QuoInt", InvalidOperation)
+		return true
+	}
+	return false
+}
+
+// quoIntTooLong reports if the integer quotient of finite x and y has more
+// digits than the precision
+func (c *Context) quoIntTooLong(x, y *Dec) bool {
+	if c.Precision <= 0 {
+		return false
+	}
+	q := new(Dec).QuoInt(x, y)
+	return digits(q) > c.Precision
+}
+
+// Sqrt sets z to the square root of x rounded to the precision of c and
+// returns z. An exact root has the smallest scale that is at least half
+// that of x. The square root of a negative number raises InvalidOperation.
+func (c *Context) Sqrt(z, x *Dec) *Dec {
+	if x.isSpecial() || x.Sign() <= 0 {
+		return c.finish(z, "Sqrt", new(Dec).Sqrt(x, (x.Scale()+1)/2, RoundDown), 0, false, x.IsNaN())
+	}
+	p := c.precision()
+	r, inexact := c.fit(p, (decExponent(x)+1)/2, func(s Scale, r Rounder) *Dec {
+		return new(Dec).Sqrt(x, s, r)
+	})
+	if !inexact && r != nil {
+		trim(r, (x.Scale()+1)/2)
+	}
+	return c.finish(z, "Sqrt", r, p, inexact, false)
+}
+
+// Exp sets z to e**x rounded to the precision of c and returns z.
+func (c *Context) Exp(z, x *Dec) *Dec {
+	if x.isSpecial() || x.Sign() == 0 {
+		return c.finish(z, "Exp", new(Dec).Exp(x, Scale(0), RoundDown), 0, false, x.IsNaN())
+	}
+	// e**x is about 10**(x*log10(e))
+	t, _ := integerPart(new(Dec).Mul(x, NewDec(big.NewInt(4342944819), 10)))
+	if c.overflows(t) {
+		return c.raise(z.SetInf(false), "Exp", Overflow|Inexact)
+	}
+	p := c.precision()
+	if underflows(t) {
+		return c.finishMath(z, "Exp", new(Dec), p, true)
+	}
+	r, inexact := c.fit(p, t.Int64()+1, func(s Scale, r Rounder) *Dec {
+		return new(Dec).Exp(x, s, r)
+	})
+	return c.finishMath(z, "Exp", r, p, inexact)
+}
+
+// overflows reports if 10**e overflows, where e is an estimate of the
+// exponent of a result of Exp or Pow
+func (c *Context) overflows(e *big.Int) bool {
+	max := int64(maxMathExponent) + 1
+	if c.MaxExponent != 0 && int64(c.MaxExponent) < max {
+		max = int64(c.MaxExponent)
+	}
+	return e.Cmp(big.NewInt(max+1)) > 0
+}
+
+// underflows reports if 10**e is too small for Exp and Pow to compute,
+// where e is an estimate of the exponent of a result
+func underflows(e *big.Int) bool {
+	return e.Cmp(big.NewInt(-maxMathExponent-2)) < 0
+}
+
+// finishMath is finish for a result of Exp or Pow from finite operands,
+// which Dec bounds to an infinity or 0 without rounding. As neither is
+// ever exactly 0, a result of 0 has underflowed.
+func (c *Context) finishMath(z *Dec, op string, r *Dec, p int, inexact bool) *Dec {
+	switch {
+	case r == nil || r.IsNaN():
+	case r.IsInf():
+		return c.raise(z.Set(r), op, Overflow|Inexact)
+	case r.Sign() == 0:
+		return c.raise(z.Set(NewDec(bigInt[0], Scale(p))), op, Underflow|Inexact)
+	}
+	return c.finish(z, op, r, p, inexact, false)
+}
+
+// Ln sets z to the natural logarithm of x rounded to the precision of c and
+// returns z. The logarithm of a negative number raises InvalidOperation.
+func (c *Context) Ln(z, x *Dec) *Dec {
+	return c.log(z, "Ln", x, (*Dec).Ln)
+}
+
+// Log10 sets z to the base 10 logarithm of x rounded to the precision of c
+// and returns z. The logarithm of a negative number raises
+// InvalidOperation.
+func (c *Context) Log10(z, x *Dec) *Dec {
+	return c.log(z, "Log10", x, (*Dec).Log10)
+}
+
+func (c *Context) log(z *Dec, op string, x *Dec, f func(z, x *Dec, s Scaler, r Rounder) *Dec) *Dec {
+	if x.isSpecial() || x.Sign() <= 0 {
+		return c.finish(z, op, f(new(Dec), x, Scale(0), RoundDown), 0, false, x.IsNaN())
+	}
+	// a logarithm close to 0 is about x-1
+	e := decExponent(new(Dec).Sub(x, NewDecInt64(1)))
+	if l := f(new(Dec), x, Scale(2), RoundDown); l.Sign() != 0 {
+		e = decExponent(l)
+	}
+	p := c.precision()
+	r, inexact := c.fit(p, e, func(s Scale, r Rounder) *Dec {
+		return f(new(Dec), x, s, r)
+	})
+	if !inexact && r != nil {
+		trim(r, 0)
+	}
+	return c.finish(z, op, r, p, inexact, false)
+}
+
+// Pow sets z to x**y rounded to the precision of c and returns z. An exact
+// power of an integer exponent n has the smallest scale that is at least n
+// times that of x. A negative number to a non-integer power raises
+// InvalidOperation.
+func (c *Context) Pow(z, x, y *Dec) *Dec {
+	nan := x.IsNaN() || y.IsNaN()
+	if x.isSpecial() || y.isSpecial() || x.Sign() == 0 || y.Sign() == 0 {
+		return c.finish(z, "Pow", new(Dec).Pow(x, y, Scale(0), RoundDown), 0, false, nan)
+	}
+	n, isInt := integerPart(y)
+	if !isInt && x.Sign() < 0 {
+		return c.raise(z.SetNaN(), "Pow", InvalidOperation)
+	}
+	// x**y is about 10**(y*log10(|x|))
+	ax := new(Dec).Abs(x)
+	yd := Scale(len(new(big.Int).Abs(n).String()))
+	l := new(Dec).Log10(ax, 2+yd, RoundDown)
+	t, _ := integerPart(l.Mul(l, y))
+	if c.overflows(t) {
+		return c.raise(z.SetInf(x.Sign() < 0 && n.Bit(0) == 1), "Pow", Overflow|Inexact)
+	}
+	p := c.precision()
+	if underflows(t) {
+		return c.finishMath(z, "Pow", new(Dec), p, true)
+	}
+	r, inexact := c.fit(p, t.Int64()+1, func(s Scale, r Rounder) *Dec {
+		return new(Dec).Pow(x, y, s, r)
+	})
+	if !inexact && r != nil {
+		ideal := Scale(0)
+		if isInt && n.Sign() > 0 && n.IsInt64() {
+			if s := int64(x.Scale()) * n.Int64(); s == int64(Scale(s)) {
+				ideal = Scale(s)
+			}
+		}
+		trim(r, ideal)
+	}
+	return c.finishMath(z, "Pow", r, p, inexact)
+}
@@ -0,0 +1,235 @@
+package dec
+
+import (
+	"testing"
+)
+
+type ctxFun func(c *Context, z, x, y *Dec) *Dec
+
+var ctxAdd = func(c *Context, z, x, y *Dec) *Dec { return c.Add(z, x, y) }
+var ctxSub = func(c *Context, z, x, y *Dec) *Dec { return c.Sub(z, x, y) }
+var ctxMul = func(c *Context, z, x, y *Dec) *Dec { return c.Mul(z, x, y) }
+var ctxQuo = func(c *Context, z, x, y *Dec) *Dec { return c.Quo(z, x, y) }
+var ctxNeg = func(c *Context, z, x, y *Dec) *Dec { return c.Neg(z, x) }
+var ctxRound = func(c *Context, z, x, y *Dec) *Dec { return c.Round(z, x, 2) }
+var ctxQuoInt = func(c *Context, z, x, y *Dec) *Dec { return c.QuoInt(z, x, y) }
+var ctxRem = func(c *Context, z, x, y *Dec) *Dec { return c.Rem(z, x, y) }
+var ctxMod = func(c *Context, z, x, y *Dec) *Dec { return c.Mod(z, x, y) }
+var ctxSqrt = func(c *Context, z, x, y *Dec) *Dec { return c.Sqrt(z, x) }
+var ctxExp = func(c *Context, z, x, y *Dec) *Dec { return c.Exp(z, x) }
+var ctxLn = func(c *Context, z, x, y *Dec) *Dec { return c.Ln(z, x) }
+var ctxLog10 = func(c *Context, z, x, y *Dec) *Dec { return c.Log10(z, x) }
+var ctxPow = func(c *Context, z, x, y *Dec) *Dec { return c.Pow(z, x, y) }
+
+var decContextTests = []struct {
+	name      string
+	f         ctxFun
+	precision int
+	x, y      string
+	z         string
+	flags     Condition
+}{
+	{"Add", ctxAdd, 0, "1.2345", "0.00006", "1.23456", 0},
+	{"Add", ctxAdd, 5, "1.2345", "0.00006", "1.2346", Inexact},
+	{"Add", ctxAdd, 5, "1.2345", "0.00000", "1.2345", 0},
+	{"Add", ctxAdd, 4, "9.9999", "0", "10.00", Inexact},
+	{"Add", ctxAdd, 2, "1234", "0", "1.2E+3", Inexact},
+	{"Add", ctxAdd, 0, "Infinity", "-Infinity", "NaN", InvalidOperation},
+	{"Add", ctxAdd, 0, "NaN", "1", "NaN", 0},
+	{"Sub", ctxSub, 3, "100", "0.1", "99.9", 0},
+	{"Sub", ctxSub, 3, "100", "0.01", "100", Inexact},
+	{"Mul", ctxMul, 0, "1.25", "1.25", "1.5625", 0},
+	{"Mul", ctxMul, 3, "1.25", "1.25", "1.56", Inexact},
+	{"Mul", ctxMul, 0, "0", "Infinity", "NaN", InvalidOperation},
+	{"Quo", ctxQuo, 0, "1", "4", "0.25", 0},
+	{"Quo", ctxQuo, 0, "1.00", "4", "0.25", 0},
+	{"Quo", ctxQuo, 0, "100", "4", "25", 0},
+	{"Quo", ctxQuo, 0, "2", "3", "0.6666666666666666666666666666666667", Inexact},
+	{"Quo", ctxQuo, 5, "1", "3", "0.33333", Inexact},
+	{"Quo", ctxQuo, 5, "-200000", "3", "-66667", Inexact},
+	{"Quo", ctxQuo, 5, "1", "0.0003", "3333.3", Inexact},
+	{"Quo", ctxQuo, 3, "9.995", "1", "10.0", Inexact},
+	{"Quo", ctxQuo, 0, "0.00", "7", "0.00", 0},
+	{"Quo", ctxQuo, 0, "-1", "0", "-Infinity", DivisionByZero},
+	{"Quo", ctxQuo, 0, "0", "0", "NaN", InvalidOperation},
+	{"Quo", ctxQuo, 0, "1", "Infinity", "0", 0},
+	{"Quo", ctxQuo, 0, "Infinity", "Infinity", "NaN", InvalidOperation},
+	{"QuoInt", ctxQuoInt, 0, "-7.5", "2", "-3", 0},
+	{"QuoInt", ctxQuoInt, 3, "12345", "10", "NaN", InvalidOperation},
+	{"QuoInt", ctxQuoInt, 3, "12345", "100", "123", 0},
+	{"QuoInt", ctxQuoInt, 0, "-1", "0", "-Infinity", DivisionByZero},
+	{"QuoInt", ctxQuoInt, 0, "0", "0", "NaN", InvalidOperation},
+	{"Rem", ctxRem, 0, "-7.5", "2", "-1.5", 0},
+	{"Rem", ctxRem, 3, "12345", "10", "NaN", InvalidOperation},
+	{"Rem", ctxRem, 0, "1", "0", "NaN", InvalidOperation},
+	{"Rem", ctxRem, 0, "Infinity", "1", "NaN", InvalidOperation},
+	{"Mod", ctxMod, 0, "-7.5", "2", "0.5", 0},
+	{"Mod", ctxMod, 2, "-0.001", "3", "3.0", Inexact},
+	{"Mod", ctxMod, 0, "NaN", "0", "NaN", 0},
+	{"Neg", ctxNeg, 2, "1.25", "", "-1.2", Inexact},
+	{"Round", ctxRound, 0, "1.255", "", "1.26", Inexact},
+	{"Round", ctxRound, 0, "1.2", "", "1.20", 0},
+	{"Sqrt", ctxSqrt, 10, "2", "", "1.414213562", Inexact},
+	{"Sqrt", ctxSqrt, 10, "0.0002", "", "0.01414213562", Inexact},
+	{"Sqrt", ctxSqrt, 0, "2.25", "", "1.5", 0},
+	{"Sqrt", ctxSqrt, 0, "100", "", "10", 0},
+	{"Sqrt", ctxSqrt, 0, "-4", "", "NaN", InvalidOperation},
+	{"Exp", ctxExp, 10, "1", "", "2.718281828", Inexact},
+	{"Exp", ctxExp, 10, "-100", "", "3.720075976E-44", Inexact},
+	{"Exp", ctxExp, 5, "-100", "", "3.7201E-44", Inexact},
+	{"Exp", ctxExp, 5, "0", "", "1", 0},
+	{"Ln", ctxLn, 10, "10", "", "2.302585093", Inexact},
+	{"Ln", ctxLn, 5, "1.000001", "", "0.0000010000", Inexact},
+	{"Ln", ctxLn, 7, "1.000001", "", "9.999995E-7", Inexact},
+	{"Ln", ctxLn, 0, "1", "", "0", 0},
+	{"Ln", ctxLn, 0, "0", "", "-Infinity", 0},
+	{"Ln", ctxLn, 0, "-1", "", "NaN", InvalidOperation},
+	{"Log10", ctxLog10, 10, "2", "", "0.3010299957", Inexact},
+	{"Log10", ctxLog10, 0, "0.001", "", "-3", 0},
+	{"Pow", ctxPow, 0, "1.5", "2", "2.25", 0},
+	{"Pow", ctxPow, 5, "1.05", "30", "4.3219", Inexact},
+	{"Pow", ctxPow, 0, "4", "0.5", "2", 0},
+	{"Pow", ctxPow, 0, "2", "-2", "0.25", 0},
+	{"Pow", ctxPow, 10, "2", "0.5", "1.414213562", Inexact},
+	{"Pow", ctxPow, 0, "-8", "0.5", "NaN", InvalidOperation},
+
+	// results beyond 10**±6000 without a MaxExponent
+	{"Exp", ctxExp, 0, "10000000000000000000000000", "", "Infinity", Overflow | Inexact},
+	{"Exp", ctxExp, 0, "-10000000000000000000000000", "", "0E-34", Underflow | Inexact},
+	{"Exp", ctxExp, 10, "13815", "", "6.001606172E+5999", Inexact},
+	{"Exp", ctxExp, 10, "13816", "", "Infinity", Overflow | Inexact},
+	{"Exp", ctxExp, 10, "-13816", "", "0E-10", Underflow | Inexact},
+	{"Pow", ctxPow, 10, "2", "1000000000", "Infinity", Overflow | Inexact},
+	{"Pow", ctxPow, 10, "-2", "1000000001", "-Infinity", Overflow | Inexact},
+	{"Pow", ctxPow, 10, "2", "-1000000000", "0E-10", Underflow | Inexact},
+	{"Pow", ctxPow, 10, "10", "6001", "Infinity", Overflow | Inexact},
+}
+
+func TestDecContext(t *testing.T) {
+	for i, test := range decContextTests {
+		c := Context{Precision: test.precision}
+		x, _ := new(Dec).SetString(test.x)
+		y, _ := new(Dec).SetString(test.y)
+		z := test.f(&c, new(Dec), x, y)
+		want, ok := new(Dec).SetString(test.z)
+		if !ok {
+			// E notation for results with a negative scale or many zeros
+			want = decFromE(test.z)
+		}
+		if z.String() != want.String() || z.Scale() != want.Scale() {
+			t.Errorf("#%d %s(%s, %s) got %s (scale %d); want %s (scale %d)",
+				i, test.name, test.x, test.y, z, z.Scale(), want, want.Scale())
+		}
+		if c.Flags != test.flags {
+			t.Errorf("#%d %s(%s, %s) got flags %q; want %q", i, test.name, test.x, test.y, c.Flags, test.flags)
+		}
+		if c.Err() != nil {
+			t.Errorf("#%d %s(%s, %s) got error %v", i, test.name, test.x, test.y, c.Err())
+		}
+	}
+}
+
+// decFromE parses a coefficient and an exponent such as 1.2E+3 or 9.9E-7
+func decFromE(s string) *Dec {
+	var coef string
+	var exp int
+	for i := range s {
+		if s[i] == 'E' {
+			coef = s[:i]
+			for _, c := range s[i+2:] {
+				exp = exp*10 + int(c-'0')
+			}
+			if s[i+1] == '-' {
+				exp = -exp
+			}
+		}
+	}
+	z, _ := new(Dec).SetString(coef)
+	return z.SetScale(z.Scale() - Scale(exp))
+}
+
+func TestDecContextTraps(t *testing.T) {
+	c := Context{Precision: 5, Traps: DivisionByZero | Overflow}
+	z := c.Quo(new(Dec), NewDecInt64(1), NewDecInt64(3))
+	if z.String() != "0.33333" || c.Err() != nil {
+		t.Errorf("got %s, %v; want 0.33333", z, c.Err())
+	}
+	z = c.Quo(new(Dec), NewDecInt64(1), NewDecInt64(0))
+	if !z.IsNaN() {
+		t.Errorf("got %s; want NaN", z)
+	}
+	// the error is sticky and the NaN propagates
+	c.Add(z, z, NewDecInt64(1))
+	err, ok := c.Err().(ConditionError)
+	if !ok || err.Op != "Quo" || err.Condition != DivisionByZero || !z.IsNaN() {
+		t.Errorf("got %s, %v; want a division by zero", z, c.Err())
+	}
+	if c.Flags != Inexact|DivisionByZero {
+		t.Errorf("got flags %q", c.Flags)
+	}
+	if err.Error() != "Context.Quo: division by zero" {
+		t.Errorf("got %q", err.Error())
+	}
+	c.ClearFlags()
+	if c.Flags != 0 || c.Err() != nil {
+		t.Errorf("flags not cleared")
+	}
+
+	c.MaxExponent = 3
+	if z := c.Mul(new(Dec), NewDecInt64(-100), NewDecInt64(10)); !z.IsNaN() || c.Err() == nil {
+		t.Errorf("got %s, %v; want an overflow", z, c.Err())
+	}
+	c.ClearFlags()
+	c.Traps = 0
+	if z := c.Exp(new(Dec), NewDecInt64(1000000)); !z.IsInf() || c.Flags != Overflow|Inexact {
+		t.Errorf("got %s, %q; want Infinity and an overflow", z, c.Flags)
+	}
+	c.ClearFlags()
+	if z := c.Mul(new(Dec), NewDecInt64(-99), NewDecInt64(10)); z.String() != "-990" || c.Flags != 0 {
+		t.Errorf("got %s, %q; want -990", z, c.Flags)
+	}
+
+	c = Context{Precision: 5, Traps: Underflow}
+	if z := c.Exp(new(Dec), NewDecInt64(-100000)); !z.IsNaN() || c.Err() == nil {
+		t.Errorf("got %s, %v; want an underflow", z, c.Err())
+	}
+	if err := c.Err(); err == nil || err.Error() != "Context.Exp: underflow" {
+		t.Errorf("got %v; want Context.Exp: underflow", err)
+	}
+}
+
+func TestDecContextDivMod(t *testing.T) {
+	c := Context{Precision: 5}
+	q, m := c.DivMod(new(Dec), NewDecInt64(-7), NewDecInt64(2), new(Dec))
+	if q.String() != "-4" || m.String() != "1" || c.Flags != 0 {
+		t.Errorf("got %s, %s, %q; want -4, 1", q, m, c.Flags)
+	}
+	q, m = c.DivMod(new(Dec), NewDecInt64(-7), NewDecInt64(2), nil)
+	if q.String() != "-4" || m != nil || c.Flags != 0 {
+		t.Errorf("got %s, %v, %q; want -4 without a modulus", q, m, c.Flags)
+	}
+	q, m = c.DivMod(new(Dec), NewDecInt64(7), NewDecInt64(-2), nil)
+	if q.String() != "-4" || c.Flags != 0 {
+		t.Errorf("got %s, %q; want -4", q, c.Flags)
+	}
+	if q = c.QuoInt(new(Dec), NewDecInt64(-7), NewDecInt64(2)); q.String() != "-3" || c.Flags != 0 {
+		t.Errorf("QuoInt got %s, %q; want -3", q, c.Flags)
+	}
+	if q = c.QuoInt(new(Dec), NewDecInt64(7), NewDecInt64(-2)); q.String() != "-3" || c.Flags != 0 {
+		t.Errorf("QuoInt got %s, %q; want -3", q, c.Flags)
+	}
+	q, m = c.DivMod(new(Dec), NewDecInt64(7), NewDecInt64(0), new(Dec))
+	if !q.IsInf() || !m.IsNaN() || c.Flags != DivisionByZero|InvalidOperation {
+		t.Errorf("got %s, %s, %q; want Infinity, NaN", q, m, c.Flags)
+	}
+}
+
+func TestDecContextRoundExact(t *testing.T) {
+	c := Context{Precision: 3, Rounder: RoundExact}
+	if z := c.Add(new(Dec), NewDecInt64(1000), NewDecInt64(0)); z.String() != "1000" || c.Flags != 0 {
+		t.Errorf("got %s, %q; want 1000", z, c.Flags)
+	}
+	if z := c.Add(new(Dec), NewDecInt64(1001), NewDecInt64(0)); !z.IsNaN() || c.Flags != Inexact {
+		t.Errorf("got %s, %q; want NaN", z, c.Flags)
+	}
+}
@@ -0,0 +1,189 @@
+package dec
+
+// This file implements conversions between Dec and the native numeric types
+// and those of math/big.
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// SetInt64 sets z to x with scale 0 and returns z.
+func (z *Dec) SetInt64(x int64) *Dec {
+	z.unscaled.SetInt64(x)
+	z.form = finite
+	return z.SetScale(0)
+}
+
+// SetUint64 sets z to x with scale 0 and returns z.
+func (z *Dec) SetUint64(x uint64) *Dec {
+	z.unscaled.SetUint64(x)
+	z.form = finite
+	return z.SetScale(0)
+}
+
+// Int64 returns the value of x truncated towards zero and reports whether it
+// is exact; that is, whether x is an integer that fits in an int64. If x is
+// out of range the result is math.MinInt64 or math.MaxInt64, and if x is NaN
+// it is 0.
+func (x *Dec) Int64() (int64, bool) {
+	switch {
+	case x.IsNaN():
+		return 0, false
+	case x.IsInf() && x.Sign() < 0:
+		return math.MinInt64, false
+	case x.IsInf():
+		return math.MaxInt64, false
+	}
+	n, exact := integerPart(x)
+	switch {
+	case n.IsInt64():
+		return n.Int64(), exact
+	case n.Sign() < 0:
+		return math.MinInt64, false
+	}
+	return math.MaxInt64, false
+}
+
+// Uint64 returns the value of x truncated towards zero and reports whether it
+// is exact; that is, whether x is an integer that fits in a uint64. If x is
+// out of range the result is 0 or math.MaxUint64, and if x is NaN it is 0.
+func (x *Dec) Uint64() (uint64, bool) {
+	switch {
+	case x.IsNaN():
+		return 0, false
+	case x.IsInf() && x.Sign() < 0:
+		return 0, false
+	case x.IsInf():
+		return math.MaxUint64, false
+	}
+	n, exact := integerPart(x)
+	switch {
+	case n.IsUint64():
+		return n.Uint64(), exact
+	case n.Sign() < 0:
+		return 0, false
+	}
+	return math.MaxUint64, false
+}
+
+// SetFloat64 sets z to the decimal with the fewest digits that converts back
+// to x, as formatted by strconv.FormatFloat(x, 'g', -1, 64), and returns z. It
+// also reports whether z equals the binary value of x exactly; 0.5 does, but
+// 0.1 does not. The scale of z is never negative, and NaN and the infinities
+// are converted to the corresponding special values.
+func (z *Dec) SetFloat64(x float64) (*Dec, bool) {
+	switch {
+	case math.IsNaN(x):
+		return z.SetNaN(), true
+	case math.IsInf(x, 0):
+		return z.SetInf(x < 0), true
+	}
+	// b is of the form d.dddde-dd
+	b := strconv.AppendFloat(make([]byte, 0, 32), math.Abs(x), 'e', -1, 64)
+	e := 0
+	for e < len(b) && b[e] != 'e' {
+		e++
+	}
+	exp, _ := strconv.Atoi(string(b[e+1:]))
+	digits := make([]byte, 0, e)
+	for _, c := range b[:e] {
+		if c != '.' {
+			digits = append(digits, c)
+		}
+	}
+	z.unscaled.SetString(string(digits), 10)
+	z.form = finite
+	if x < 0 {
+		z.unscaled.Neg(&z.unscaled)
+	}
+	s := Scale(len(digits) - 1 - exp)
+	if s < 0 {
+		z.unscaled.Mul(&z.unscaled, exp10(-s))
+		s = 0
+	}
+	z.SetScale(s)
+	r, _ := z.Rat()
+	return z, r.Cmp(new(big.Rat).SetFloat64(x)) == 0
+}
+
+// SetFloat64Round sets z to the binary value of x with the scale obtained
+// from the given Scaler, rounded using the given Rounder, and returns z. It
+// also reports whether z equals x exactly; with ScaleQuoExact and RoundExact,
+// z always does. As SetBigRat, the Scaler is passed the numerator and
+// denominator of x. If the result from the rounder is nil, it returns nil and
+// false, and the value of z is undefined.
+func (z *Dec) SetFloat64Round(x float64, s Scaler, r Rounder) (*Dec, bool) {
+	switch {
+	case math.IsNaN(x):
+		return z.SetNaN(), true
+	case math.IsInf(x, 0):
+		return z.SetInf(x < 0), true
+	}
+	return z.SetBigRat(new(big.Rat).SetFloat64(x), s, r)
+}
+
+// SetBigFloat sets z to the value of x with the scale obtained from the given
+// Scaler, rounded using the given Rounder, and returns z. It also reports
+// whether z equals x exactly. As SetBigRat, the Scaler is passed the
+// numerator and denominator of x, and an infinite x is converted to the
+// corresponding infinity. If the result from the rounder is nil, it returns
+// nil and false, and the value of z is undefined.
+func (z *Dec) SetBigFloat(x *big.Float, s Scaler, r Rounder) (*Dec, bool) {
+	if x.IsInf() {
+		return z.SetInf(x.Signbit()), true
+	}
+	xr, _ := x.Rat(nil)
+	return z.SetBigRat(xr, s, r)
+}
+
+// SetBigRat sets z to the value of x with the scale obtained from the given
+// Scaler, rounded using the given Rounder, and returns z. It also reports
+// whether z equals x exactly. The Scaler is passed the numerator and
+// denominator of x as Decs with scale 0, so that ScaleQuoExact with
+// RoundExact converts x exactly whenever it is a finite decimal. If the
+// result from the rounder is nil, it returns nil and false, and the value of
+// z is undefined.
+func (z *Dec) SetBigRat(x *big.Rat, s Scaler, r Rounder) (*Dec, bool) {
+	num, den := NewDec(x.Num(), 0), NewDec(x.Denom(), 0)
+	q, remNum, remDen := new(Dec).quoRem(num, den, s.Scale(num, den), true,
+		new(big.Int), new(big.Int))
+	var zz *Dec
+	if r.UseRemainder() {
+		zz = r.Round(new(Dec), q, remNum, remDen)
+	} else {
+		zz = r.Round(new(Dec), q, nil, nil)
+	}
+	if zz == nil {
+		return nil, false
+	}
+	return z.Set(zz), remNum.Sign() == 0
+}
+
+// Float64 returns the float64 value nearest to x, rounding half to even, and
+// reports whether it is exact. If x is out of the range of float64 the result
+// is an infinity, and NaN and the infinities are converted exactly.
+func (x *Dec) Float64() (float64, bool) {
+	switch {
+	case x.IsNaN():
+		return math.NaN(), true
+	case x.IsInf():
+		return math.Inf(x.Sign()), true
+	}
+	r, _ := x.Rat()
+	return r.Float64()
+}
+
+// Rat returns the value of x as a big.Rat and reports whether x is finite. If
+// x is NaN or an infinity the result is nil.
+func (x *Dec) Rat() (*big.Rat, bool) {
+	if x.isSpecial() {
+		return nil, false
+	}
+	if x.Scale() <= 0 {
+		n, _ := integerPart(x)
+		return new(big.Rat).SetInt(n), true
+	}
+	return new(big.Rat).SetFrac(x.Unscaled(), exp10(x.Scale())), true
+}
@@ -0,0 +1,188 @@
+package dec
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+var decSetFloat64Tests = []struct {
+	x     float64
+	z     string
+	exact bool
+}{
+	{0, "0", true},
+	{math.Copysign(0, -1), "0", true},
+	{0.5, "0.5", true},
+	{-0.1, "-0.1", false},
+	{1.0 / 3, "0.3333333333333333", false},
+	{123456789, "123456789", true},
+	{1e21, "1000000000000000000000", true},
+	{1e23, "100000000000000000000000", false},
+	{2.5e-8, "0.000000025", false},
+	{math.SmallestNonzeroFloat64, "0." + strings.Repeat("0", 323) + "5", false},
+	{math.NaN(), "NaN", true},
+	{math.Inf(-1), "-Infinity", true},
+}
+
+func TestDecSetFloat64(t *testing.T) {
+	for i, test := range decSetFloat64Tests {
+		z, exact := new(Dec).SetFloat64(test.x)
+		if z.String() != test.z || exact != test.exact {
+			t.Errorf("#%d SetFloat64(%g) got %s, %v; want %s, %v", i, test.x, z, exact, test.z, test.exact)
+		}
+		if f, _ := z.Float64(); f != test.x && !math.IsNaN(test.x) {
+			t.Errorf("#%d Float64(%s) got %g; want %g", i, z, f, test.x)
+		}
+	}
+}
+
+var decSetFloat64RoundTests = []struct {
+	x     float64
+	s     Scaler
+	r     Rounder
+	z     string // nil if ""
+	exact bool
+}{
+	{0.1, Scale(2), RoundHalfEven, "0.10", false},
+	{0.1, ScaleQuoExact, RoundExact, "0.1000000000000000055511151231257827021181583404541015625", true},
+	{0.1, Scale(3), RoundExact, "", false},
+	{2.675, Scale(2), RoundHalfUp, "2.67", false},
+	{-1.5, Scale(0), RoundHalfEven, "-2", false},
+	{0.25, Scale(4), RoundDown, "0.2500", true},
+	{1e20, Scale(0), RoundExact, "100000000000000000000", true},
+	{math.Inf(1), Scale(2), RoundExact, "Infinity", true},
+}
+
+func TestDecSetFloat64Round(t *testing.T) {
+	for i, test := range decSetFloat64RoundTests {
+		z, exact := new(Dec).SetFloat64Round(test.x, test.s, test.r)
+		switch {
+		case z == nil && test.z != "":
+			t.Errorf("#%d SetFloat64Round(%g) got nil; want %s", i, test.x, test.z)
+		case z != nil && (z.String() != test.z || exact != test.exact):
+			t.Errorf("#%d SetFloat64Round(%g) got %s, %v; want %s, %v", i, test.x, z, exact, test.z, test.exact)
+		}
+	}
+}
+
+var decFloat64Tests = []struct {
+	x     string
+	f     float64
+	exact bool
+}{
+	{"0.00", 0, true},
+	{"-1.25", -1.25, true},
+	{"0.1", 0.1, false},
+	{"9007199254740993", 9007199254740992, false},
+	{"1" + strings.Repeat("0", 400), math.Inf(1), false},
+	{"-Infinity", math.Inf(-1), true},
+}
+
+func TestDecFloat64(t *testing.T) {
+	for i, test := range decFloat64Tests {
+		f, exact := decString(test.x).Float64()
+		if f != test.f || exact != test.exact {
+			t.Errorf("#%d Float64(%s) got %g, %v; want %g, %v", i, test.x, f, exact, test.f, test.exact)
+		}
+	}
+	if f, exact := new(Dec).SetNaN().Float64(); !math.IsNaN(f) || !exact {
+		t.Errorf("Float64(NaN) got %g, %v; want NaN, true", f, exact)
+	}
+	if f, exact := NewDec(big.NewInt(3), -2).Float64(); f != 300 || !exact {
+		t.Errorf("Float64(3E+2) got %g, %v; want 300, true", f, exact)
+	}
+}
+
+var decIntTests = []struct {
+	x      string
+	i      int64
+	iExact bool
+	u      uint64
+	uExact bool
+}{
+	{"0.00", 0, true, 0, true},
+	{"42", 42, true, 42, true},
+	{"-42.9", -42, false, 0, false},
+	{"42.9", 42, false, 42, false},
+	{"9223372036854775807", math.MaxInt64, true, math.MaxInt64, true},
+	{"9223372036854775808", math.MaxInt64, false, 1 << 63, true},
+	{"-9223372036854775808.0", math.MinInt64, true, 0, false},
+	{"-9223372036854775809", math.MinInt64, false, 0, false},
+	{"18446744073709551616", math.MaxInt64, false, math.MaxUint64, false},
+	{"NaN", 0, false, 0, false},
+	{"-Infinity", math.MinInt64, false, 0, false},
+	{"Infinity", math.MaxInt64, false, math.MaxUint64, false},
+}
+
+func TestDecInt64(t *testing.T) {
+	for i, test := range decIntTests {
+		x := decString(test.x)
+		if n, exact := x.Int64(); n != test.i || exact != test.iExact {
+			t.Errorf("#%d Int64(%s) got %d, %v; want %d, %v", i, x, n, exact, test.i, test.iExact)
+		}
+		if n, exact := x.Uint64(); n != test.u || exact != test.uExact {
+			t.Errorf("#%d Uint64(%s) got %d, %v; want %d, %v", i, x, n, exact, test.u, test.uExact)
+		}
+	}
+	if n, exact := NewDec(big.NewInt(-12), -3).Int64(); n != -12000 || !exact {
+		t.Errorf("Int64(-12E+3) got %d, %v; want -12000, true", n, exact)
+	}
+	if z := new(Dec).SetNaN().SetInt64(-7); z.String() != "-7" {
+		t.Errorf("SetInt64(-7) got %s", z)
+	}
+	if z := new(Dec).SetInf(false).SetUint64(math.MaxUint64); z.String() != "18446744073709551615" {
+		t.Errorf("SetUint64(MaxUint64) got %s", z)
+	}
+}
+
+var decSetBigRatTests = []struct {
+	x     *big.Rat
+	s     Scaler
+	r     Rounder
+	z     string // nil if ""
+	exact bool
+}{
+	{big.NewRat(1, 4), ScaleQuoExact, RoundExact, "0.25", true},
+	{big.NewRat(-3, 8), Scale(5), RoundExact, "-0.37500", true},
+	{big.NewRat(1, 3), ScaleQuoExact, RoundExact, "", false},
+	{big.NewRat(1, 3), Scale(4), RoundHalfUp, "0.3333", false},
+	{big.NewRat(-2, 3), Scale(4), RoundHalfUp, "-0.6667", false},
+	{big.NewRat(-2, 3), Scale(0), RoundDown, "0", false},
+	{big.NewRat(100, 1), Scale(-1), RoundExact, "100", true},
+}
+
+func TestDecSetBigRat(t *testing.T) {
+	for i, test := range decSetBigRatTests {
+		z, exact := new(Dec).SetBigRat(test.x, test.s, test.r)
+		switch {
+		case z == nil && test.z != "":
+			t.Errorf("#%d SetBigRat(%s) got nil; want %s", i, test.x, test.z)
+		case z != nil && (z.String() != test.z || exact != test.exact):
+			t.Errorf("#%d SetBigRat(%s) got %s, %v; want %s, %v", i, test.x, z, exact, test.z, test.exact)
+		}
+		if z != nil && exact {
+			if r, _ := z.Rat(); r.Cmp(test.x) != 0 {
+				t.Errorf("#%d Rat(%s) got %s; want %s", i, z, r, test.x)
+			}
+		}
+	}
+	if r, ok := new(Dec).SetInf(true).Rat(); r != nil || ok {
+		t.Errorf("Rat(-Infinity) got %v, %v; want nil, false", r, ok)
+	}
+}
+
+func TestDecSetBigFloat(t *testing.T) {
+	x := new(big.Float).SetPrec(200).Quo(big.NewFloat(1), big.NewFloat(1024))
+	if z, exact := new(Dec).SetBigFloat(x, ScaleQuoExact, RoundExact); z.String() != "0.0009765625" || !exact {
+		t.Errorf("got %s, %v; want 0.0009765625, true", z, exact)
+	}
+	if z, exact := new(Dec).SetBigFloat(x, Scale(4), RoundHalfEven); z.String() != "0.0010" || exact {
+		t.Errorf("got %s, %v; want 0.0010, false", z, exact)
+	}
+	x.SetInf(true)
+	if z, exact := new(Dec).SetBigFloat(x, Scale(4), RoundExact); z.String() != "-Infinity" || !exact {
+		t.Errorf("got %s, %v; want -Infinity, true", z, exact)
+	}
+}
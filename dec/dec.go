@@ -24,6 +24,7 @@ import (
 	"io"
 	"math/big"
 	"strings"
+	"unicode"
 )
 
 // A Dec represents a signed multi-precision decimal.
@@ -48,11 +49,29 @@ import (
 //
 // The zero value for a Dec represents the value 0 with scale 0.
 //
+// A Dec can also hold the special values NaN, +Infinity and -Infinity,
+// as the NUMERIC type of PostgreSQL can. They follow the same rules:
+// NaN is equal to itself and greater than every other value, the
+// result of an operation with a NaN operand is NaN, and operations
+// on infinities with no defined result such as Inf-Inf or 0*Inf are
+// NaN. See SetNaN and SetInf.
+//
 type Dec struct {
 	unscaled big.Int
 	scale    Scale
+	form     form
 }
 
+// form distinguishes finite values from the special values. The sign
+// of an infinity is the sign of its unscaled value.
+type form byte
+
+const (
+	finite form = iota
+	nan
+	inf
+)
+
 // Scale represents the type used for the scale of a Dec.
 type Scale int32
 
@@ -117,8 +136,10 @@ func (x *Dec) SetScale(scale Scale) *Dec {
 }
 
 // SetScale sets the unscaled value of x, with the scale unchanged.
+// x is always finite afterwards.
 func (x *Dec) SetUnscaled(unscaled *big.Int) *Dec {
 	x.unscaled.Set(unscaled)
+	x.form = finite
 	return x
 }
 
@@ -128,16 +149,55 @@ func (z *Dec) Set(x *Dec) *Dec {
 	if z != x {
 		z.SetUnscaled(x.Unscaled())
 		z.SetScale(x.Scale())
+		z.form = x.form
 	}
 	return z
 }
 
+// SetNaN sets z to NaN and returns z.
+func (z *Dec) SetNaN() *Dec {
+	z.unscaled.SetInt64(0)
+	z.scale = 0
+	z.form = nan
+	return z
+}
+
+// SetInf sets z to -Infinity if neg is true, otherwise +Infinity, and
+// returns z.
+func (z *Dec) SetInf(neg bool) *Dec {
+	if neg {
+		z.unscaled.SetInt64(-1)
+	} else {
+		z.unscaled.SetInt64(1)
+	}
+	z.scale = 0
+	z.form = inf
+	return z
+}
+
+// IsNaN reports whether x is NaN.
+func (x *Dec) IsNaN() bool {
+	return x.form == nan
+}
+
+// IsInf reports whether x is +Infinity or -Infinity. The sign of an
+// infinity is reported by Sign.
+func (x *Dec) IsInf() bool {
+	return x.form == inf
+}
+
+// isSpecial reports whether x is NaN or an infinity.
+func (x *Dec) isSpecial() bool {
+	return x.form != finite
+}
+
 // Sign returns:
 //
 //	-1 if x <  0
 //	 0 if x == 0
 //	+1 if x >  0
 //
+// The sign of NaN is 0.
 func (x *Dec) Sign() int {
 	return x.Unscaled().Sign()
 }
@@ -146,6 +206,7 @@ func (x *Dec) Sign() int {
 func (z *Dec) Neg(x *Dec) *Dec {
 	z.SetScale(x.Scale())
 	z.Unscaled().Neg(x.Unscaled())
+	z.form = x.form
 	return z
 }
 
@@ -155,41 +216,99 @@ func (z *Dec) Neg(x *Dec) *Dec {
 //    0 if x == y
 //   +1 if x >  y
 //
+// NaN is equal to NaN and greater than any other value.
 func (x *Dec) Cmp(y *Dec) int {
+	if x.isSpecial() || y.isSpecial() {
+		return cmpSpecial(x, y)
+	}
 	xx, yy := upscale(x, y)
 	return xx.Unscaled().Cmp(yy.Unscaled())
 }
 
+// rank orders the special values relative to finite values
+func (x *Dec) rank() int {
+	switch {
+	case x.form == nan:
+		return 2
+	case x.form == inf:
+		return x.Sign()
+	}
+	return 0
+}
+
+func cmpSpecial(x, y *Dec) int {
+	rx, ry := x.rank(), y.rank()
+	switch {
+	case rx < ry:
+		return -1
+	case rx > ry:
+		return 1
+	}
+	return 0
+}
+
 // Abs sets z to |x| (the absolute value of x) and returns z.
 func (z *Dec) Abs(x *Dec) *Dec {
 	z.SetScale(x.Scale())
 	z.Unscaled().Abs(x.Unscaled())
+	z.form = x.form
 	return z
 }
 
 // Add sets z to the sum x+y and returns z.
 // The scale of z is the greater of the scales of x and y.
 func (z *Dec) Add(x, y *Dec) *Dec {
+	if x.isSpecial() || y.isSpecial() {
+		return z.addSpecial(x.rank(), y.rank())
+	}
 	xx, yy := upscale(x, y)
 	z.SetScale(xx.Scale())
 	z.Unscaled().Add(xx.Unscaled(), yy.Unscaled())
+	z.form = finite
 	return z
 }
 
 // Sub sets z to the difference x-y and returns z.
 // The scale of z is the greater of the scales of x and y.
 func (z *Dec) Sub(x, y *Dec) *Dec {
+	if x.isSpecial() || y.isSpecial() {
+		ry := y.rank()
+		if ry != 2 {
+			ry = -ry
+		}
+		return z.addSpecial(x.rank(), ry)
+	}
 	xx, yy := upscale(x, y)
 	z.SetScale(xx.Scale())
 	z.Unscaled().Sub(xx.Unscaled(), yy.Unscaled())
+	z.form = finite
 	return z
 }
 
+// addSpecial sets z to the sum of operands of the given rank where at
+// least one is special
+func (z *Dec) addSpecial(rx, ry int) *Dec {
+	switch {
+	case rx == 2 || ry == 2 || rx*ry == -1:
+		return z.SetNaN()
+	case rx != 0:
+		return z.SetInf(rx < 0)
+	}
+	return z.SetInf(ry < 0)
+}
+
 // Mul sets z to the product x*y and returns z.
 // The scale of z is the sum of the scales of x and y.
 func (z *Dec) Mul(x, y *Dec) *Dec {
+	if x.isSpecial() || y.isSpecial() {
+		if x.IsNaN() || y.IsNaN() || x.Sign() == 0 || y.Sign() == 0 {
+			return z.SetNaN()
+		}
+		return z.SetInf(x.Sign() != y.Sign())
+	}
 	z.SetScale(x.Scale() + y.Scale())
 	z.Unscaled().Mul(x.Unscaled(), y.Unscaled())
+	z.form = finite
 	return z
 }
 
@@ -209,6 +328,9 @@ func (z *Dec) Round(x *Dec, s Scale, r Rounder) *Dec {
 //
 // See Rounder for details on the various ways for rounding.
 func (z *Dec) Quo(x, y *Dec, scaler Scaler, rounder Rounder) *Dec {
+	if x.isSpecial() || y.isSpecial() {
+		return z.quoSpecial(x, y, scaler)
+	}
 	s := scaler.Scale(x, y)
 	var zzz *Dec
 	if rounder.UseRemainder() {
@@ -224,6 +346,25 @@ func (z *Dec) Quo(x, y *Dec, scaler Scaler, rounder Rounder) *Dec {
 	return z.Set(zzz)
 }
 
+// quoSpecial sets z to x/y where at least one is special. A finite
+// value divided by an infinity is zero with the scale from scaler, an
+// infinity divided by a finite value is an infinity.
+func (z *Dec) quoSpecial(x, y *Dec, scaler Scaler) *Dec {
+	switch {
+	case x.IsNaN() || y.IsNaN() || (x.IsInf() && y.IsInf()):
+		return z.SetNaN()
+	case y.IsInf():
+		z.unscaled.SetInt64(0)
+		z.form = finite
+		return z.SetScale(scaler.Scale(x, y))
+	}
+	sign := y.Sign()
+	if sign == 0 {
+		sign = 1
+	}
+	return z.SetInf(x.Sign() != sign)
+}
+
 // QuoExact(x, y) is a shorthand for Quo(x, y, ScaleQuoExact, RoundExact).
 // If x/y can be expressed as a Dec without rounding, QuoExact sets z to the
 // quotient x/y and returns z. Otherwise, it returns nil and the value of z is
@@ -232,6 +373,77 @@ func (z *Dec) QuoExact(x, y *Dec) *Dec {
 	return z.Quo(x, y, ScaleQuoExact, RoundExact)
 }
 
+// QuoInt sets z to the quotient x/y truncated towards zero, with scale 0,
+// and returns z. It is the div function of PostgreSQL. An infinity divided by
+// a finite value is an infinity and a finite value divided by an infinity is
+// 0. If y is zero and x is finite, a division-by-zero run-time panic occurs.
+func (z *Dec) QuoInt(x, y *Dec) *Dec {
+	if x.isSpecial() || y.isSpecial() {
+		return z.quoSpecial(x, y, Scale(0))
+	}
+	z.quoRem(x, y, 0, false, nil, nil)
+	z.form = finite
+	return z
+}
+
+// Rem sets z to the remainder x - y*QuoInt(x, y) and returns z. The remainder
+// has the sign of x, as the % operator of PostgreSQL, and the greater of the
+// scales of x and y. The remainder of an infinity is NaN and the remainder of
+// a finite x by an infinity is x. If y is zero and x is finite, a
+// division-by-zero run-time panic occurs.
+func (z *Dec) Rem(x, y *Dec) *Dec {
+	if x.isSpecial() || y.isSpecial() {
+		return z.remSpecial(x, y)
+	}
+	q := new(Dec).QuoInt(x, y)
+	return z.Sub(x, q.Mul(q, y))
+}
+
+// Mod sets z to the modulus x - y*floor(x/y) and returns z. Unlike Rem, the
+// modulus has the sign of y. The scale is the greater of the scales of x and
+// y. The modulus of an infinity is NaN and the modulus of a finite x by an
+// infinity is x if x is zero or has the sign of y, or y otherwise. If y is
+// zero and x is finite, a division-by-zero run-time panic occurs.
+func (z *Dec) Mod(x, y *Dec) *Dec {
+	_, m := new(Dec).DivMod(x, y, z)
+	return m
+}
+
+// DivMod sets z to the quotient floor(x/y) and m to the modulus
+// x - y*floor(x/y), as Mod, and returns the pair (z, m). z and m must be
+// different.
+func (z *Dec) DivMod(x, y, m *Dec) (*Dec, *Dec) {
+	q, r := new(Dec), new(Dec)
+	switch {
+	case x.isSpecial() || y.IsNaN():
+		q.quoSpecial(x, y, Scale(0))
+		r.remSpecial(x, y)
+	case y.IsInf() && (x.Sign() == 0 || x.Sign() == y.Sign()):
+		q.SetUnscaled(bigInt[0])
+		r.Set(x)
+	case y.IsInf():
+		q.SetUnscaled(intSign[0])
+		r.Set(y)
+	default:
+		q.QuoInt(x, y)
+		r.Sub(x, new(Dec).Mul(q, y))
+	}
+	if !r.isSpecial() && r.Sign() != 0 && r.Sign() != y.Sign() {
+		q.Sub(q, NewDecInt64(1))
+		r.Add(r, y)
+	}
+	return z.Set(q), m.Set(r)
+}
+
+// remSpecial sets z to the remainder of x and y where at least one is
+// special
+func (z *Dec) remSpecial(x, y *Dec) *Dec {
+	if x.isSpecial() || y.IsNaN() {
+		return z.SetNaN()
+	}
+	return z.Set(x)
+}
+
 // quoRem sets z to the quotient x/y with the scale s, and if useRem is true,
 // it sets remNum and remDen to the numerator and denominator of the remainder.
 // It returns z, remNum and remDen.
@@ -371,48 +583,13 @@ func appendZeros(s []byte, n Scale) []byte {
 	return s
 }
 
+// String returns the decimal representation of x, or "NaN",
+// "Infinity" or "-Infinity" for the special values.
 func (x *Dec) String() string {
 	if x == nil {
 		return "<nil>"
 	}
-	scale := x.Scale()
-	s := []byte(x.Unscaled().String())
-	if scale <= 0 {
-		if scale != 0 && x.unscaled.Sign() != 0 {
-			s = appendZeros(s, -scale)
-		}
-		return string(s)
-	}
-	negbit := Scale(-((x.Sign() - 1) / 2))
-	// scale > 0
-	lens := Scale(len(s))
-	if lens-negbit <= scale {
-		ss := make([]byte, 0, scale+2)
-		if negbit == 1 {
-			ss = append(ss, '-')
-		}
-		ss = append(ss, '0', '.')
-		ss = appendZeros(ss, scale-lens+negbit)
-		ss = append(ss, s[negbit:]...)
-		return string(ss)
-	}
-	// lens > scale
-	ss := make([]byte, 0, lens+1)
-	ss = append(ss, s[:lens-scale]...)
-	ss = append(ss, '.')
-	ss = append(ss, s[lens-scale:]...)
-	return string(ss)
-}
-
-// Format is a support routine for fmt.Formatter. It accepts the decimal
-// formats 'd' and 'f', and handles both equivalently.
-// Width, precision, flags and bases 2, 8, 16 are not supported.
-func (x *Dec) Format(s fmt.State, ch rune) {
-	if ch != 'd' && ch != 'f' && ch != 'v' && ch != 's' {
-		fmt.Fprintf(s, "%%!%c(dec.Dec=%s)", ch, x.String())
-		return
-	}
-	fmt.Fprintf(s, x.String())
+	return string(x.AppendString(nil))
 }
 
 func (z *Dec) scan(r io.RuneScanner) (*Dec, error) {
@@ -462,15 +639,37 @@ loop:
 	if !ok {
 		return nil, fmt.Errorf("invalid decimal: %s", string(unscaled))
 	}
+	z.form = finite
 	return z, nil
 }
 
+// setSpecial sets z to the special value named by s and reports
+// whether s is one.
+func (z *Dec) setSpecial(s string) bool {
+	switch strings.ToLower(s) {
+	case "nan":
+		z.SetNaN()
+	case "infinity", "+infinity", "inf", "+inf":
+		z.SetInf(false)
+	case "-infinity", "-inf":
+		z.SetInf(true)
+	default:
+		return false
+	}
+	return true
+}
+
 // SetString sets z to the value of s, interpreted as a decimal (base 10),
-// and returns z and a boolean indicating success. The scale of z is the
+// and returns z and a boolean indicating success. The special values
+// are accepted as "NaN", "Infinity", "+Infinity", "-Infinity", "inf"
+// and "-inf" in any case, as PostgreSQL does. The scale of z is the
 // number of digits after the decimal point (including any trailing 0s),
 // or 0 if there is no decimal point. If SetString fails, the value of z
 // is undefined but the returned value is nil.
 func (z *Dec) SetString(s string) (*Dec, bool) {
+	if z.setSpecial(s) {
+		return z, true
+	}
 	r := strings.NewReader(s)
 	_, err := z.scan(r)
 	if err != nil {
@@ -489,17 +688,48 @@ func (z *Dec) SetString(s string) (*Dec, bool) {
 // handles both equivalently. Bases 2, 8, 16 are not supported.
 // The scale of z is the number of digits after the decimal point
 // (including any trailing 0s), or 0 if there is no decimal point.
+// The special values are accepted as they are by SetString.
 func (z *Dec) Scan(s fmt.ScanState, ch rune) error {
 	if ch != 'd' && ch != 'f' && ch != 's' && ch != 'v' {
 		return fmt.Errorf("Dec.Scan: invalid verb '%c'", ch)
 	}
 	s.SkipSpace()
-	_, err := z.scan(s)
+	// a sign is read here, as only one rune can be unread to look for
+	// the letter starting a special value after it
+	sign := ""
+	r, _, err := s.ReadRune()
+	if err == nil && (r == '+' || r == '-') {
+		sign = string(r)
+		r, _, err = s.ReadRune()
+	}
+	if err == nil {
+		s.UnreadRune()
+		switch r {
+		case '+', '-':
+			return fmt.Errorf("no digits read")
+		case 'n', 'N', 'i', 'I':
+			name, err := s.Token(false, unicode.IsLetter)
+			if err != nil {
+				return err
+			}
+			if !z.setSpecial(sign + string(name)) {
+				return fmt.Errorf("Dec.Scan: invalid decimal %q", sign+string(name))
+			}
+			return nil
+		}
+	}
+	_, err = z.scan(s)
+	if err == nil && sign == "-" {
+		z.Neg(z)
+	}
 	return err
 }
 
-// Gob encoding version
+// Gob encoding version. Finite values are still written with version
+// 1, the special values are written with version 2 which adds a byte
+// for the form before the version.
 const decGobVersion byte = 1
+const decGobSpecialVersion byte = 2
 
 func scaleBytes(s Scale) []byte {
 	buf := make([]byte, scaleSize)
@@ -526,8 +756,11 @@ func (x *Dec) GobEncode() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	buf = append(append(buf, scaleBytes(x.Scale())...), decGobVersion)
-	return buf, nil
+	buf = append(buf, scaleBytes(x.Scale())...)
+	if x.isSpecial() {
+		return append(buf, byte(x.form), decGobSpecialVersion), nil
+	}
+	return append(buf, decGobVersion), nil
 }
 
 // GobDecode implements the gob.GobDecoder interface.
@@ -536,14 +769,31 @@ func (z *Dec) GobDecode(buf []byte) error {
 		return fmt.Errorf("Dec.GobDecode: no data")
 	}
 	b := buf[len(buf)-1]
-	if b != decGobVersion {
+	f := finite
+	switch b {
+	case decGobVersion:
+		buf = buf[:len(buf)-1]
+	case decGobSpecialVersion:
+		if len(buf) < 2 {
+			return fmt.Errorf("Dec.GobDecode: no form")
+		}
+		f = form(buf[len(buf)-2])
+		if f != nan && f != inf {
+			return fmt.Errorf("Dec.GobDecode: invalid form %d", f)
+		}
+		buf = buf[:len(buf)-2]
+	default:
 		return fmt.Errorf("Dec.GobDecode: encoding version %d not supported", b)
 	}
-	l := len(buf) - scaleSize - 1
+	l := len(buf) - scaleSize
+	if l < 0 {
+		return fmt.Errorf("Dec.GobDecode: no scale")
+	}
 	err := z.Unscaled().GobDecode(buf[:l])
 	if err != nil {
 		return err
 	}
 	z.SetScale(scale(buf[l : l+scaleSize]))
+	z.form = f
 	return nil
 }
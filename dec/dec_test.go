@@ -132,6 +132,60 @@ func TestDecQuoRem(t *testing.T) {
 	}
 }
 
+var decQuoIntTests = []struct {
+	x, y          string
+	quo, rem, mod string
+	floor         string
+}{
+	{"7", "2", "3", "1", "1", "3"},
+	{"-7", "2", "-3", "-1", "1", "-4"},
+	{"7", "-2", "-3", "1", "-1", "-4"},
+	{"-7", "-2", "3", "-1", "-1", "3"},
+	{"6", "-2", "-3", "0", "0", "-3"},
+	{"7.5", "-2", "-3", "1.5", "-0.5", "-4"},
+	{"-0.25", "0.1", "-2", "-0.05", "0.05", "-3"},
+	{"0.00", "-3", "0", "0.00", "0.00", "0"},
+	{"123456789012345678901234567890", "7", "17636684144620811271604938270", "0", "0",
+		"17636684144620811271604938270"},
+	{"NaN", "2", "NaN", "NaN", "NaN", "NaN"},
+	{"2", "NaN", "NaN", "NaN", "NaN", "NaN"},
+	{"Infinity", "2", "Infinity", "NaN", "NaN", "Infinity"},
+	{"-Infinity", "2", "-Infinity", "NaN", "NaN", "-Infinity"},
+	{"5.5", "Infinity", "0", "5.5", "5.5", "0"},
+	{"-5.5", "Infinity", "0", "-5.5", "Infinity", "-1"},
+	{"5.5", "-Infinity", "0", "5.5", "-Infinity", "-1"},
+	{"0", "-Infinity", "0", "0", "0", "0"},
+}
+
+func TestDecQuoInt(t *testing.T) {
+	for i, test := range decQuoIntTests {
+		x, y := decString(test.x), decString(test.y)
+		if got := new(Dec).QuoInt(x, y).String(); got != test.quo {
+			t.Errorf("#%d QuoInt(%s, %s) got %s; want %s", i, x, y, got, test.quo)
+		}
+		if got := new(Dec).Rem(x, y).String(); got != test.rem {
+			t.Errorf("#%d Rem(%s, %s) got %s; want %s", i, x, y, got, test.rem)
+		}
+		if got := new(Dec).Mod(x, y).String(); got != test.mod {
+			t.Errorf("#%d Mod(%s, %s) got %s; want %s", i, x, y, got, test.mod)
+		}
+		q, m := new(Dec).DivMod(x, y, new(Dec))
+		if q.String() != test.floor || m.String() != test.mod {
+			t.Errorf("#%d DivMod(%s, %s) got %s, %s; want %s, %s", i, x, y, q, m, test.floor, test.mod)
+		}
+	}
+
+	// aliasing
+	x, y := NewDecInt64(-7), NewDecInt64(2)
+	if x.Rem(x, y); x.String() != "-1" {
+		t.Errorf("Rem(x, x, y) got %s; want -1", x)
+	}
+	x.SetUnscaled(big.NewInt(-7))
+	if q, m := x.DivMod(x, y, y); q.String() != "-4" || m.String() != "1" {
+		t.Errorf("DivMod(x, y, y) got %s, %s; want -4, 1", q, m)
+	}
+}
+
 var decRoundTests = [...]struct {
 	in  *Dec
 	s   Scale
@@ -328,6 +382,26 @@ var decScanNextTests = []struct {
 	{"0.0g", true, 'g'},
 }
 
+func TestDecScanSpecial(t *testing.T) {
+	for i, s := range []string{"NaN", "Infinity", "-Infinity", "inf", "+inf", "-INF", "nan"} {
+		x := decString(s)
+		var z Dec
+		if _, err := fmt.Sscan(x.String(), &z); err != nil || z.String() != x.String() {
+			t.Errorf("#%d Sscan(%s) got %s, %v", i, x, &z, err)
+		}
+	}
+	var a, b, c Dec
+	n, err := fmt.Sscan("NaN -Infinity -1.5", &a, &b, &c)
+	if n != 3 || err != nil || !a.IsNaN() || b.String() != "-Infinity" || c.String() != "-1.5" {
+		t.Errorf("got %d %s %s %s, %v", n, &a, &b, &c, err)
+	}
+	for _, s := range []string{"nope", "-nan", "--1", "-+1", "+NaN"} {
+		if _, err := fmt.Sscan(s, new(Dec)); err == nil {
+			t.Errorf("Sscan(%q) got no error", s)
+		}
+	}
+}
+
 func TestDecScanNext(t *testing.T) {
 	for i, test := range decScanNextTests {
 		rdr := strings.NewReader(test.in)
@@ -385,3 +459,97 @@ func TestDecGobEncoding(t *testing.T) {
 		}
 	}
 }
+
+func TestDecGobEncodingSpecial(t *testing.T) {
+	var medium bytes.Buffer
+	enc := gob.NewEncoder(&medium)
+	dec := gob.NewDecoder(&medium)
+	for i, test := range []string{"NaN", "Infinity", "-Infinity"} {
+		var tx Dec
+		tx.SetString(test)
+		if err := enc.Encode(&tx); err != nil {
+			t.Errorf("#%d: encoding failed: %s", i, err)
+		}
+		var rx Dec
+		if err := dec.Decode(&rx); err != nil {
+			t.Errorf("#%d: decoding failed: %s", i, err)
+		}
+		if rx.String() != test {
+			t.Errorf("#%d: transmission failed: got %s want %s", i, &rx, test)
+		}
+	}
+}
+
+func decString(s string) *Dec {
+	z, ok := new(Dec).SetString(s)
+	if !ok {
+		panic(s)
+	}
+	return z
+}
+
+var decSpecialTests = []struct {
+	x, y      string
+	sum, diff string
+	prod, quo string
+	cmp       int
+}{
+	{"NaN", "1", "NaN", "NaN", "NaN", "NaN", 1},
+	{"1", "NaN", "NaN", "NaN", "NaN", "NaN", -1},
+	{"NaN", "NaN", "NaN", "NaN", "NaN", "NaN", 0},
+	{"NaN", "Infinity", "NaN", "NaN", "NaN", "NaN", 1},
+	{"Infinity", "1", "Infinity", "Infinity", "Infinity", "Infinity", 1},
+	{"-Infinity", "1", "-Infinity", "-Infinity", "-Infinity", "-Infinity", -1},
+	{"1", "Infinity", "Infinity", "-Infinity", "Infinity", "0", -1},
+	{"-1.5", "-Infinity", "-Infinity", "Infinity", "Infinity", "0", 1},
+	{"Infinity", "Infinity", "Infinity", "NaN", "Infinity", "NaN", 0},
+	{"Infinity", "-Infinity", "NaN", "Infinity", "-Infinity", "NaN", 1},
+	{"-Infinity", "-Infinity", "-Infinity", "NaN", "Infinity", "NaN", 0},
+	{"Infinity", "0", "Infinity", "Infinity", "NaN", "Infinity", 1},
+	{"-Infinity", "-2", "-Infinity", "-Infinity", "Infinity", "Infinity", -1},
+}
+
+func TestDecSpecial(t *testing.T) {
+	for i, test := range decSpecialTests {
+		x, y := decString(test.x), decString(test.y)
+		if got := new(Dec).Add(x, y).String(); got != test.sum {
+			t.Errorf("#%d %s+%s got %s; want %s", i, x, y, got, test.sum)
+		}
+		if got := new(Dec).Sub(x, y).String(); got != test.diff {
+			t.Errorf("#%d %s-%s got %s; want %s", i, x, y, got, test.diff)
+		}
+		if got := new(Dec).Mul(x, y).String(); got != test.prod {
+			t.Errorf("#%d %s*%s got %s; want %s", i, x, y, got, test.prod)
+		}
+		if got := new(Dec).Quo(x, y, Scale(0), RoundHalfUp).String(); got != test.quo {
+			t.Errorf("#%d %s/%s got %s; want %s", i, x, y, got, test.quo)
+		}
+		if got := x.Cmp(y); got != test.cmp {
+			t.Errorf("#%d cmp(%s, %s) got %d; want %d", i, x, y, got, test.cmp)
+		}
+	}
+
+	x := new(Dec).SetNaN()
+	if !x.IsNaN() || x.IsInf() || x.Sign() != 0 {
+		t.Errorf("NaN predicates wrong")
+	}
+	x.SetInf(true)
+	if x.IsNaN() || !x.IsInf() || x.Sign() != -1 {
+		t.Errorf("-Infinity predicates wrong")
+	}
+	if new(Dec).Neg(x).String() != "Infinity" || new(Dec).Abs(x).String() != "Infinity" {
+		t.Errorf("Neg or Abs of -Infinity wrong")
+	}
+	x.SetUnscaled(big.NewInt(5))
+	if x.IsInf() || x.String() != "5" {
+		t.Errorf("SetUnscaled did not make x finite")
+	}
+	for _, s := range []string{"nan", "inf", "-INF", "+Infinity"} {
+		if _, ok := new(Dec).SetString(s); !ok {
+			t.Errorf("%s not accepted", s)
+		}
+		if _, ok := new(Dec).SetStringBytes([]byte(s)); !ok {
+			t.Errorf("%s not accepted by SetStringBytes", s)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package dec
+
+// This file implements the encoding interfaces for text, JSON and binary.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// MarshalText implements the encoding.TextMarshaler interface. The text is
+// the same as String.
+func (x Dec) MarshalText() ([]byte, error) {
+	return x.AppendString(nil), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It
+// accepts the same text as SetString. If the text is invalid z is unchanged.
+func (z *Dec) UnmarshalText(text []byte) error {
+	var d Dec
+	if _, ok := d.SetStringBytes(text); !ok {
+		return fmt.Errorf("Dec.UnmarshalText: invalid decimal %q", text)
+	}
+	*z = d
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON string so that decoders reading numbers as floating point lose no
+// precision. JSONNumber writes a JSON number instead.
+func (x Dec) MarshalJSON() ([]byte, error) {
+	buf := append(make([]byte, 0, 24), '"')
+	buf = x.AppendString(buf)
+	return append(buf, '"'), nil
+}
+
+// JSONNumber is a Dec that is written to JSON as a number when it is finite.
+// NaN and the infinities are still written as strings, as JSON has no
+// numbers for them. The methods of Dec are promoted, so it is read from JSON
+// in the same way.
+type JSONNumber struct {
+	Dec
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (x JSONNumber) MarshalJSON() ([]byte, error) {
+	if x.isSpecial() {
+		return x.Dec.MarshalJSON()
+	}
+	return x.AppendString(nil), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts a JSON
+// number, including one with an exponent such as 1.5e3, and a string
+// holding any text accepted by SetString. The JSON null leaves z unchanged,
+// as does an invalid value.
+func (z *Dec) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	num := data
+	if len(data) != 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		num = []byte(s)
+	}
+	var d Dec
+	if !d.setJSONNumber(num) {
+		return fmt.Errorf("Dec.UnmarshalJSON: invalid decimal %s", data)
+	}
+	*z = d
+	return nil
+}
+
+// setJSONNumber sets z to the value of b, which is accepted by SetString
+// or is a number with an exponent, and reports whether it is valid
+func (z *Dec) setJSONNumber(b []byte) bool {
+	e := bytes.IndexAny(b, "eE")
+	if e < 0 {
+		_, ok := z.SetStringBytes(b)
+		return ok
+	}
+	exp, err := strconv.ParseInt(string(b[e+1:]), 10, 32)
+	if err != nil || e == 0 || !isDigitOrPoint(b[e-1]) {
+		return false
+	}
+	if _, ok := z.SetStringBytes(b[:e]); !ok {
+		return false
+	}
+	s := int64(z.Scale()) - exp
+	if s != int64(Scale(s)) {
+		return false
+	}
+	z.SetScale(Scale(s))
+	return true
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The
+// encoding is the same as GobEncode.
+func (x Dec) MarshalBinary() ([]byte, error) {
+	return x.GobEncode()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (z *Dec) UnmarshalBinary(data []byte) error {
+	return z.GobDecode(data)
+}
@@ -0,0 +1,115 @@
+package dec
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+var decEncodingTests = []string{
+	"0", "0.00", "-1.5", "123456789012345678901234567890.123456789",
+	"NaN", "Infinity", "-Infinity",
+}
+
+func TestDecText(t *testing.T) {
+	for i, s := range decEncodingTests {
+		x := decString(s)
+		b, err := x.MarshalText()
+		if err != nil || string(b) != s {
+			t.Errorf("#%d MarshalText(%s) got %q, %v", i, s, b, err)
+		}
+		var z Dec
+		if err := z.UnmarshalText(b); err != nil || z.String() != s {
+			t.Errorf("#%d UnmarshalText(%q) got %s, %v", i, b, &z, err)
+		}
+	}
+	z := NewDecInt64(7)
+	if err := z.UnmarshalText([]byte("1.2.3")); err == nil || z.String() != "7" {
+		t.Errorf("got %s, %v; want 7 and an error", z, err)
+	}
+}
+
+func TestDecBinary(t *testing.T) {
+	for i, s := range decEncodingTests {
+		b, err := decString(s).MarshalBinary()
+		if err != nil {
+			t.Errorf("#%d MarshalBinary(%s) got %v", i, s, err)
+			continue
+		}
+		var z Dec
+		if err := z.UnmarshalBinary(b); err != nil || z.String() != s {
+			t.Errorf("#%d UnmarshalBinary(%x) got %s, %v; want %s", i, b, &z, err, s)
+		}
+	}
+}
+
+var decJSONTests = []struct {
+	in  string
+	out string // "" if invalid
+}{
+	{`"1.50"`, "1.50"},
+	{`1.50`, "1.50"},
+	{`-12`, "-12"},
+	{`1.5e3`, "1500"},
+	{`1.25E-2`, "0.0125"},
+	{`-2e+1`, "-20"},
+	{`"NaN"`, "NaN"},
+	{`"-Infinity"`, "-Infinity"},
+	{`"1e2"`, "100"},
+	{`"abc"`, ""},
+	{`1e`, ""},
+	{`"NaNe2"`, ""},
+	{`{}`, ""},
+	{`1e9999999999`, ""},
+}
+
+func TestDecUnmarshalJSON(t *testing.T) {
+	for i, test := range decJSONTests {
+		z := NewDecInt64(7)
+		err := z.UnmarshalJSON([]byte(test.in))
+		switch {
+		case test.out == "" && (err == nil || z.String() != "7"):
+			t.Errorf("#%d UnmarshalJSON(%s) got %s, %v; want an error", i, test.in, z, err)
+		case test.out != "" && (err != nil || z.String() != test.out):
+			t.Errorf("#%d UnmarshalJSON(%s) got %s, %v; want %s", i, test.in, z, err, test.out)
+		}
+	}
+	z := NewDecInt64(7)
+	if err := z.UnmarshalJSON([]byte("null")); err != nil || z.String() != "7" {
+		t.Errorf("UnmarshalJSON(null) got %s, %v; want 7", z, err)
+	}
+}
+
+func TestDecMarshalJSON(t *testing.T) {
+	v := struct {
+		A Dec
+		B *Dec
+		C Dec
+	}{*decString("1.50"), decString("-2"), *decString("NaN")}
+	b, err := json.Marshal(v)
+	want := `{"A":"1.50","B":"-2","C":"NaN"}`
+	if err != nil || string(b) != want {
+		t.Errorf("got %s, %v; want %s", b, err, want)
+	}
+
+	n := struct {
+		A JSONNumber
+		B *JSONNumber
+		C JSONNumber
+	}{JSONNumber{*decString("1.50")}, &JSONNumber{*decString("-2")}, JSONNumber{*decString("NaN")}}
+	b, err = json.Marshal(n)
+	want = `{"A":1.50,"B":-2,"C":"NaN"}`
+	if err != nil || string(b) != want {
+		t.Errorf("got %s, %v; want %s", b, err, want)
+	}
+	var w struct{ A, B, C Dec }
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&w); err != nil ||
+		w.A.String() != "1.50" || w.B.String() != "-2" || !w.C.IsNaN() {
+		t.Errorf("got %s, %s, %s, %v", &w.A, &w.B, &w.C, err)
+	}
+	var m struct{ A, B, C JSONNumber }
+	if err := json.Unmarshal(b, &m); err != nil ||
+		m.A.String() != "1.50" || m.B.String() != "-2" || !m.C.IsNaN() {
+		t.Errorf("got %s, %s, %s, %v", &m.A, &m.B, &m.C, err)
+	}
+}
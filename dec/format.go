@@ -0,0 +1,333 @@
+package dec
+
+// This file implements formatting of decimals for display.
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Format is a support routine for fmt.Formatter. It accepts the verbs
+//
+//	'd', 'f', 's', 'v'  fixed-point, as String
+//	'e', 'E'            scientific notation, such as -1.2345e+03
+//	'g', 'G'            %e for exponents less than -4 or at least the
+//	                    precision, or 21 without one, and %f otherwise
+//
+// The precision is the number of digits after the decimal point for the
+// fixed-point verbs and %e, and the number of significant digits for %g,
+// and the value is rounded to it with RoundHalfUp, half away from zero as
+// the round function of PostgreSQL does; Formatter takes another Rounder.
+// Without a precision all the digits of the value are written. %g drops trailing zeros after rounding, unless the '#' flag is
+// given. The width and the flags '+', ' ', '-' and '0' are handled as for
+// floating-point values. Bases 2, 8 and 16 are not supported.
+func (x *Dec) Format(s fmt.State, ch rune) {
+	x.format(s, ch, RoundHalfUp)
+}
+
+// Formatter formats Dec as Dec.Format does, rounding to the precision of
+// the verb with Rounder instead of RoundHalfUp, as in
+//
+//	fmt.Sprintf("%.2f", dec.Formatter{Dec: x, Rounder: dec.RoundHalfEven})
+//
+// If Rounder is nil RoundHalfUp is used. If it returns nil, as RoundExact
+// does when digits would be dropped, all the digits of the value are
+// written.
+type Formatter struct {
+	Dec     *Dec
+	Rounder Rounder
+}
+
+// Format implements the fmt.Formatter interface.
+func (f Formatter) Format(s fmt.State, ch rune) {
+	r := f.Rounder
+	if r == nil {
+		r = RoundHalfUp
+	}
+	f.Dec.format(s, ch, r)
+}
+
+// format is Format rounding with r
+func (x *Dec) format(s fmt.State, ch rune, r Rounder) {
+	var body []byte
+	switch ch {
+	case 'd', 'f', 's', 'v', 'e', 'E', 'g', 'G':
+	default:
+		fmt.Fprintf(s, "%%!%c(dec.Dec=%s)", ch, x.String())
+		return
+	}
+	if x == nil {
+		fmt.Fprint(s, "<nil>")
+		return
+	}
+	prec, hasPrec := s.Precision()
+	switch {
+	case x.isSpecial():
+		body = x.AppendString(nil)
+		if x.Sign() < 0 {
+			body = body[1:]
+		}
+	case ch == 'e' || ch == 'E':
+		body = appendExp(nil, x, prec, hasPrec, r, byte(ch))
+	case ch == 'g' || ch == 'G':
+		body = appendGeneral(nil, x, prec, hasPrec, s.Flag('#'), r, byte(ch-'g'+'e'))
+	default:
+		y := x
+		if hasPrec {
+			y = roundFormat(x, Scale(prec), r)
+		}
+		body = appendFixed(nil, y, ".")
+	}
+	sign := ""
+	switch {
+	case x.Sign() < 0:
+		sign = "-"
+	case s.Flag('+'):
+		sign = "+"
+	case s.Flag(' '):
+		sign = " "
+	}
+	width, _ := s.Width()
+	pad := width - len(sign) - len(body)
+	buf := make([]byte, 0, len(sign)+len(body)+pad+1)
+	switch {
+	case pad <= 0:
+		buf = append(append(buf, sign...), body...)
+	case s.Flag('-'):
+		buf = append(append(buf, sign...), body...)
+		buf = append(buf, fmt.Sprintf("%*s", pad, "")...)
+	case s.Flag('0') && !x.isSpecial():
+		buf = appendZeros(append(buf, sign...), Scale(pad))
+		buf = append(buf, body...)
+	default:
+		buf = append(buf, fmt.Sprintf("%*s", pad, "")...)
+		buf = append(append(buf, sign...), body...)
+	}
+	s.Write(buf)
+}
+
+// roundFormat returns x rounded to scale s with r, or x itself if the
+// result from r is nil
+func roundFormat(x *Dec, s Scale, r Rounder) *Dec {
+	if y := new(Dec).Round(x, s, r); y != nil {
+		return y
+	}
+	return x
+}
+
+// appendFixed appends the digits of |x| with the decimal point mark
+func appendFixed(buf []byte, x *Dec, mark string) []byte {
+	intPart, frac := fixedParts(x)
+	buf = append(buf, intPart...)
+	if len(frac) != 0 {
+		buf = append(buf, mark...)
+		buf = append(buf, frac...)
+	}
+	return buf
+}
+
+// fixedParts returns the digits of |x| before and after the decimal point.
+// The integer part is at least "0".
+func fixedParts(x *Dec) (intPart, frac []byte) {
+	d := new(big.Int).Abs(x.Unscaled()).Append(nil, 10)
+	s := x.Scale()
+	switch {
+	case s <= 0 && x.Sign() == 0:
+		return d, nil
+	case s <= 0:
+		return appendZeros(d, -s), nil
+	case Scale(len(d)) <= s:
+		return []byte{'0'}, append(appendZeros(nil, s-Scale(len(d))), d...)
+	}
+	return d[:Scale(len(d))-s], d[Scale(len(d))-s:]
+}
+
+// sigDigits returns the digits of |x| and the exponent of the first one
+func sigDigits(x *Dec) ([]byte, int64) {
+	d := new(big.Int).Abs(x.Unscaled()).Append(nil, 10)
+	if x.Sign() == 0 {
+		return d, 0
+	}
+	return d, int64(len(d)) - 1 - int64(x.Scale())
+}
+
+// roundSig returns the digits of |x| rounded to n significant digits with r
+// and the exponent of the first one. If r returns nil, all the digits of x
+// are returned.
+func roundSig(x *Dec, n int, r Rounder) ([]byte, int64) {
+	d, exp := sigDigits(x)
+	s := int64(n) - 1 - exp
+	switch {
+	case x.Sign() == 0 || s != int64(Scale(s)):
+		for len(d) < n {
+			d = append(d, '0')
+		}
+	default:
+		if y := new(Dec).Round(x, Scale(s), r); y != nil {
+			d, exp = sigDigits(y)
+			// a value rounded up to a power of ten has an extra 0
+			d = d[:n]
+		}
+	}
+	return d, exp
+}
+
+// appendExp appends |x| in scientific notation with prec digits after the
+// decimal point, rounded with r, or all of its digits if hasPrec is false
+func appendExp(buf []byte, x *Dec, prec int, hasPrec bool, r Rounder, e byte) []byte {
+	d, exp := sigDigits(x)
+	if hasPrec {
+		d, exp = roundSig(x, prec+1, r)
+	}
+	return appendMantExp(buf, d, exp, e)
+}
+
+// appendMantExp appends the digits d with the decimal point after the
+// first one, followed by the exponent
+func appendMantExp(buf []byte, d []byte, exp int64, e byte) []byte {
+	buf = append(buf, d[0])
+	if len(d) > 1 {
+		buf = append(buf, '.')
+		buf = append(buf, d[1:]...)
+	}
+	buf = append(buf, e)
+	if exp < 0 {
+		buf = append(buf, '-')
+		exp = -exp
+	} else {
+		buf = append(buf, '+')
+	}
+	if exp < 10 {
+		buf = append(buf, '0')
+	}
+	return strconv.AppendInt(buf, exp, 10)
+}
+
+// appendGeneral appends |x| as %g does, rounded with r, with e as the
+// exponent character
+func appendGeneral(buf []byte, x *Dec, prec int, hasPrec, sharp bool, r Rounder, e byte) []byte {
+	d, exp := sigDigits(x)
+	eprec := int64(21)
+	if hasPrec {
+		if prec == 0 {
+			prec = 1
+		}
+		d, exp = roundSig(x, prec, r)
+		eprec = int64(prec)
+		if !sharp {
+			for len(d) > 1 && d[len(d)-1] == '0' {
+				d = d[:len(d)-1]
+			}
+		}
+	}
+	if exp < -4 || exp >= eprec {
+		return appendMantExp(buf, d, exp, e)
+	}
+	if exp < 0 {
+		buf = append(buf, '0', '.')
+		buf = appendZeros(buf, Scale(-exp-1))
+		return append(buf, d...)
+	}
+	if n := int(exp) + 1; len(d) <= n {
+		return appendZeros(append(buf, d...), Scale(n-len(d)))
+	}
+	buf = append(buf, d[:exp+1]...)
+	buf = append(buf, '.')
+	return append(buf, d[exp+1:]...)
+}
+
+// FormatOptions describes how to write a Dec for display, such as on an
+// invoice. The zero value writes the same text as String.
+type FormatOptions struct {
+	// Scale, if not nil, gives the number of digits after the decimal
+	// mark as Scale.Scale(x, x); the value is rounded or padded with
+	// zeros to it. Use a Scale value such as Scale(2) for a fixed scale.
+	Scale Scaler
+
+	// Rounder rounds the value to the scale. If it is nil RoundHalfUp is
+	// used, as by Format. If it returns nil, as RoundExact does when digits would be
+	// dropped, all the digits of the value are written.
+	Rounder Rounder
+
+	// GroupSeparator is written between groups of GroupSize digits of the
+	// integer part, counted from the decimal mark. If it is empty the
+	// digits are not grouped.
+	GroupSeparator string
+
+	// GroupSize is the number of digits in a group, or 3 if it is zero.
+	GroupSize int
+
+	// DecimalMark separates the integer and fractional parts, "." if it
+	// is empty.
+	DecimalMark string
+
+	// CurrencySymbol is written before the digits, after any sign, or
+	// after the digits if CurrencyAfter is true. It should include any
+	// space that separates it from the digits, as in " €".
+	CurrencySymbol string
+	CurrencyAfter  bool
+}
+
+// Format returns x formatted as described by o. NaN and the infinities are
+// written as String does.
+func (o *FormatOptions) Format(x *Dec) string {
+	return string(o.Append(nil, x))
+}
+
+// Append appends x formatted as described by o to buf and returns the
+// extended buffer.
+func (o *FormatOptions) Append(buf []byte, x *Dec) []byte {
+	if x.isSpecial() {
+		return x.AppendString(buf)
+	}
+	y := x
+	if o.Scale != nil {
+		r := o.Rounder
+		if r == nil {
+			r = RoundHalfUp
+		}
+		y = roundFormat(x, o.Scale.Scale(x, x), r)
+	}
+	if y.Sign() < 0 {
+		buf = append(buf, '-')
+	}
+	if !o.CurrencyAfter {
+		buf = append(buf, o.CurrencySymbol...)
+	}
+	intPart, frac := fixedParts(y)
+	buf = o.appendGrouped(buf, intPart)
+	if len(frac) != 0 {
+		mark := o.DecimalMark
+		if mark == "" {
+			mark = "."
+		}
+		buf = append(buf, mark...)
+		buf = append(buf, frac...)
+	}
+	if o.CurrencyAfter {
+		buf = append(buf, o.CurrencySymbol...)
+	}
+	return buf
+}
+
+// appendGrouped appends the digits d separated into groups
+func (o *FormatOptions) appendGrouped(buf []byte, d []byte) []byte {
+	if o.GroupSeparator == "" {
+		return append(buf, d...)
+	}
+	size := o.GroupSize
+	if size <= 0 {
+		size = 3
+	}
+	first := len(d) % size
+	if first == 0 {
+		first = size
+	}
+	buf = append(buf, d[:first]...)
+	for i := first; i < len(d); i += size {
+		buf = append(buf, o.GroupSeparator...)
+		buf = append(buf, d[i:i+size]...)
+	}
+	return buf
+}
@@ -0,0 +1,120 @@
+package dec
+
+import (
+	"fmt"
+	"testing"
+)
+
+var decFormatTests = []struct {
+	format string
+	x      string
+	out    string
+}{
+	{"%v", "-1.50", "-1.50"},
+	{"%s", "0.001", "0.001"},
+	{"%d", "12", "12"},
+	{"%.2f", "1.005", "1.01"},
+	{"%.2f", "-1.005", "-1.01"},
+	{"%.0f", "2.5", "3"},
+	{"%.4f", "1.5", "1.5000"},
+	{"%.2f", "-0.001", "-0.00"},
+	{"%8.2f", "-3.14159", "   -3.14"},
+	{"%-8.2f|", "3.14159", "3.14    |"},
+	{"%08.2f", "-3.14159", "-0003.14"},
+	{"%+.1f", "2", "+2.0"},
+	{"% .1f", "2", " 2.0"},
+	{"%5v", "12", "   12"},
+	{"%e", "-1234.5", "-1.2345e+03"},
+	{"%.2e", "1234.5", "1.23e+03"},
+	{"%.1E", "0.00996", "1.0E-02"},
+	{"%.0e", "9.5", "1e+01"},
+	{"%.1e", "-1.25", "-1.3e+00"},
+	{"%.3e", "0", "0.000e+00"},
+	{"%e", "0.000123", "1.23e-04"},
+	{"%g", "1234.50", "1234.50"},
+	{"%g", "0.00001", "1e-05"},
+	{"%g", "123000000000000000000000", "1.23000000000000000000000e+23"},
+	{"%.3g", "1234.5", "1.23e+03"},
+	{"%.3g", "0.012345", "0.0123"},
+	{"%.4g", "1.5", "1.5"},
+	{"%#.4g", "1.5", "1.500"},
+	{"%.2g", "99.9", "1e+02"},
+	{"%.2g", "-0.125", "-0.13"},
+	{"%.5G", "0.0000012", "1.2E-06"},
+	{"%10.2f", "NaN", "       NaN"},
+	{"%010f", "-Infinity", " -Infinity"},
+	{"%+f", "Infinity", "+Infinity"},
+	{"%x", "1", "%!x(dec.Dec=1)"},
+}
+
+func TestDecFormat(t *testing.T) {
+	for i, test := range decFormatTests {
+		if got := fmt.Sprintf(test.format, decString(test.x)); got != test.out {
+			t.Errorf("#%d Sprintf(%q, %s) got %q; want %q", i, test.format, test.x, got, test.out)
+		}
+	}
+	if got := fmt.Sprintf("%v", (*Dec)(nil)); got != "<nil>" {
+		t.Errorf("got %q; want <nil>", got)
+	}
+}
+
+var decFormatterTests = []struct {
+	format  string
+	x       string
+	rounder Rounder
+	out     string
+}{
+	{"%.2f", "1.005", nil, "1.01"},
+	{"%.2f", "1.005", RoundHalfEven, "1.00"},
+	{"%.2f", "-1.009", RoundDown, "-1.00"},
+	{"%8.1f", "2.25", RoundHalfEven, "     2.2"},
+	{"%.1e", "-1.25", RoundHalfEven, "-1.2e+00"},
+	{"%.2g", "0.125", RoundHalfEven, "0.12"},
+	{"%.2g", "0.121", RoundUp, "0.13"},
+	{"%.2f", "1.234", RoundExact, "1.234"},
+	{"%.2f", "1.2", RoundExact, "1.20"},
+	{"%.2f", "NaN", RoundHalfEven, "NaN"},
+}
+
+func TestDecFormatter(t *testing.T) {
+	for i, test := range decFormatterTests {
+		f := Formatter{Dec: decString(test.x), Rounder: test.rounder}
+		if got := fmt.Sprintf(test.format, f); got != test.out {
+			t.Errorf("#%d Sprintf(%q, %s) got %q; want %q", i, test.format, test.x, got, test.out)
+		}
+	}
+}
+
+var decFormatOptionsTests = []struct {
+	o   FormatOptions
+	x   string
+	out string
+}{
+	{FormatOptions{}, "-1234567.891", "-1234567.891"},
+	{FormatOptions{GroupSeparator: ","}, "-1234567.891", "-1,234,567.891"},
+	{FormatOptions{GroupSeparator: ","}, "123", "123"},
+	{FormatOptions{GroupSeparator: ","}, "0.5", "0.5"},
+	{FormatOptions{Scale: Scale(2), GroupSeparator: ",", CurrencySymbol: "$"}, "-1234.565", "-$1,234.57"},
+	{FormatOptions{Scale: Scale(2), Rounder: RoundHalfEven, GroupSeparator: ".", DecimalMark: ",",
+		CurrencySymbol: " €", CurrencyAfter: true}, "1234567.125", "1.234.567,12 €"},
+	{FormatOptions{Scale: Scale(0), GroupSeparator: "'"}, "999999.5", "1'000'000"},
+	{FormatOptions{Scale: Scale(2), GroupSeparator: ",", GroupSize: 4}, "123456789", "1,2345,6789.00"},
+	{FormatOptions{Scale: Scale(2), Rounder: RoundExact}, "1.234", "1.234"},
+	{FormatOptions{Scale: Scale(1), Rounder: RoundDown}, "-1.99", "-1.9"},
+	{FormatOptions{Scale: Scale(0)}, "2.5", "3"},
+	{FormatOptions{Scale: Scale(2), CurrencySymbol: "$"}, "-0.001", "$0.00"},
+	{FormatOptions{Scale: ScaleQuoExact, CurrencySymbol: "$"}, "NaN", "NaN"},
+	{FormatOptions{Scale: Scale(-2)}, "1234", "1200"},
+}
+
+func TestDecFormatOptions(t *testing.T) {
+	for i, test := range decFormatOptionsTests {
+		if got := test.o.Format(decString(test.x)); got != test.out {
+			t.Errorf("#%d Format(%s) got %q; want %q", i, test.x, got, test.out)
+		}
+	}
+	o := FormatOptions{GroupSeparator: ","}
+	if got := string(o.Append([]byte("total: "), NewDecInt64(1000))); got != "total: 1,000" {
+		t.Errorf("got %q", got)
+	}
+}
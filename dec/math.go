@@ -0,0 +1,519 @@
+package dec
+
+// This file implements square roots, powers, exponentials and logarithms.
+//
+// The transcendental functions are evaluated in fixed point, as a big.Int n
+// holding the value n * 10**(-w), with enough guard digits that the error is
+// less than mathGuardUlps units of the last place. The result is then rounded
+// to the scale from the Scaler using the Rounder. If the approximation is too
+// close to a rounding boundary to decide the direction, it is recomputed with
+// twice the guard digits.
+
+import (
+	"math"
+	"math/big"
+)
+
+// mathGuardUlps bounds the error of the fixed point approximations, in units
+// of the last place
+const mathGuardUlps = 2
+
+// mathGuardDigits is the initial number of digits computed beyond the scale
+// of the result, and mathMaxGuardDigits the number beyond which an
+// approximation on a boundary is rounded as if it was just beyond it, away
+// from zero
+const mathGuardDigits = 8
+const mathMaxGuardDigits = 1024
+
+// maxMathExponent bounds the magnitude of the results of Exp and Pow, whose
+// digits would otherwise take unbounded time and memory to compute. As in
+// PostgreSQL, a result estimated to be more than 10**maxMathExponent is an
+// infinity and one less than 10**-maxMathExponent is 0.
+const maxMathExponent = 6000
+
+// Sqrt sets z to the square root of x, with the scale obtained from
+// scaler.Scale(x, x), rounded using the given Rounder, and returns z. The
+// result is always rounded correctly, and is exact for RoundExact when the
+// square root is a decimal of that scale.
+//
+// The square root of a negative number or -Infinity is NaN. If the result
+// from the rounder is nil, Sqrt also returns nil, and the value of z is
+// undefined.
+func (z *Dec) Sqrt(x *Dec, scaler Scaler, rounder Rounder) *Dec {
+	switch {
+	case x.IsNaN() || x.Sign() < 0:
+		return z.SetNaN()
+	case x.IsInf():
+		return z.SetInf(false)
+	}
+	s := scaler.Scale(x, x)
+	// compute at least one digit more than the scale and enough that
+	// the radicand is an integer
+	d := Scale(1)
+	if 2*(s+d) < x.Scale() {
+		d = (x.Scale()+1)/2 - s
+	}
+	m := new(big.Int).Mul(x.Unscaled(), exp10(2*(s+d)-x.Scale()))
+	n := new(big.Int).Sqrt(m)
+	inexact := 0
+	if new(big.Int).Mul(n, n).Cmp(m) != 0 {
+		inexact = 1
+	}
+	return z.roundFixed(n, s, d, inexact, rounder)
+}
+
+// Exp sets z to e**x, with the scale obtained from scaler.Scale(x, x),
+// rounded using the given Rounder, and returns z. Exp(-Infinity) is 0 and
+// Exp(+Infinity) is +Infinity. A result of more than 10**6000 is +Infinity
+// and one of less than 10**-6000 is 0, as in PostgreSQL. If the result from
+// the rounder is nil, Exp also returns nil, and the value of z is undefined.
+func (z *Dec) Exp(x *Dec, scaler Scaler, rounder Rounder) *Dec {
+	switch {
+	case x.IsNaN():
+		return z.SetNaN()
+	case x.IsInf() && x.Sign() > 0:
+		return z.SetInf(false)
+	}
+	s := scaler.Scale(x, x)
+	switch {
+	case x.IsInf():
+		return z.SetUnscaled(bigInt[0]).SetScale(s)
+	case x.Sign() == 0:
+		return z.Round(NewDecInt64(1), s, rounder)
+	}
+	xf, _ := x.Float64()
+	switch e := xf * math.Log10E; {
+	case e > maxMathExponent:
+		return z.SetInf(false)
+	case e < -maxMathExponent:
+		return z.SetUnscaled(bigInt[0]).SetScale(s)
+	}
+	return z.approx(s, 1, rounder, func(w Scale) *big.Int {
+		return expFixed(x, w)
+	})
+}
+
+// Ln sets z to the natural logarithm of x, with the scale obtained from
+// scaler.Scale(x, x), rounded using the given Rounder, and returns z.
+// Ln(0) is -Infinity and the logarithm of a negative number is NaN. If the
+// result from the rounder is nil, Ln also returns nil, and the value of z is
+// undefined.
+func (z *Dec) Ln(x *Dec, scaler Scaler, rounder Rounder) *Dec {
+	if z.logSpecial(x) {
+		return z
+	}
+	s := scaler.Scale(x, x)
+	sign := x.Cmp(NewDecInt64(1))
+	if sign == 0 {
+		return z.SetUnscaled(bigInt[0]).SetScale(s)
+	}
+	return z.approx(s, sign, rounder, func(w Scale) *big.Int {
+		return lnFixed(x, w)
+	})
+}
+
+// Log10 sets z to the base 10 logarithm of x, with the scale obtained from
+// scaler.Scale(x, x), rounded using the given Rounder, and returns z. The
+// result is exact when x is a power of 10. Log10(0) is -Infinity and the
+// logarithm of a negative number is NaN. If the result from the rounder is
+// nil, Log10 also returns nil, and the value of z is undefined.
+func (z *Dec) Log10(x *Dec, scaler Scaler, rounder Rounder) *Dec {
+	if z.logSpecial(x) {
+		return z
+	}
+	s := scaler.Scale(x, x)
+	if e, ok := powerOf10(x); ok {
+		return z.Round(NewDecInt64(e), s, rounder)
+	}
+	return z.approx(s, x.Cmp(NewDecInt64(1)), rounder, func(w Scale) *big.Int {
+		// |log10(x)| has about as many integer digits as the exponent
+		g := w + 4 + digitsOf(decExponent(x))
+		n := lnFixed(x, g)
+		n.Mul(n, exp10(w))
+		return n.Quo(n, ln10Fixed(g))
+	})
+}
+
+// logSpecial sets z to the logarithm of x and reports true if x is special,
+// zero or negative
+func (z *Dec) logSpecial(x *Dec) bool {
+	switch {
+	case x.IsNaN() || x.Sign() < 0:
+		z.SetNaN()
+	case x.IsInf():
+		z.SetInf(false)
+	case x.Sign() == 0:
+		z.SetInf(true)
+	default:
+		return false
+	}
+	return true
+}
+
+// Pow sets z to x**y, with the scale obtained from scaler.Scale(x, y),
+// rounded using the given Rounder, and returns z.
+//
+// If y is an integer the power is computed exactly before rounding, so the
+// result is always rounded correctly. Otherwise x must not be negative, and
+// the result is also rounded correctly when the power is a rational number,
+// such as 4**0.5 or 8**(-1.5).
+//
+// As for math.Pow, a negative number to a non-integer power is NaN, 0 to a
+// negative power is +Infinity and x**0 is 1 for any x. The remaining special
+// cases follow PostgreSQL, as does the bound on the result: a power of more
+// than 10**6000 is an infinity and one of less than 10**-6000 is 0. If the
+// result from the rounder is nil, Pow also returns nil, and the value of z is
+// undefined.
+func (z *Dec) Pow(x, y *Dec, scaler Scaler, rounder Rounder) *Dec {
+	if x.isSpecial() || y.isSpecial() {
+		return z.powSpecial(x, y, scaler.Scale(x, y), rounder)
+	}
+	s := scaler.Scale(x, y)
+	n, isInt := integerPart(y)
+	switch {
+	case y.Sign() == 0:
+		return z.Round(NewDecInt64(1), s, rounder)
+	case x.Sign() == 0 && y.Sign() < 0:
+		return z.SetInf(false)
+	case x.Sign() == 0:
+		return z.SetUnscaled(bigInt[0]).SetScale(s)
+	case !isInt && x.Sign() < 0:
+		return z.SetNaN()
+	}
+	sign := 1
+	if x.Sign() < 0 && n.Bit(0) == 1 {
+		sign = -1
+	}
+	ax := new(Dec).Abs(x)
+	if ax.Cmp(NewDecInt64(1)) == 0 {
+		return z.Round(NewDecInt64(int64(sign)), s, rounder)
+	}
+	// y multiplies the error of the logarithm, and the result has about
+	// y*ln(x)/ln(10) integer digits
+	yd := Scale(len(new(big.Int).Abs(n).String()))
+	t := new(Dec).Mul(y, NewDec(lnFixed(ax, 2+yd), 2+yd))
+	tf, _ := t.Float64()
+	switch e := tf * math.Log10E; {
+	case e > maxMathExponent:
+		return z.SetInf(sign < 0)
+	case e < -maxMathExponent:
+		return z.SetUnscaled(bigInt[0]).SetScale(s)
+	}
+	if isInt && n.IsInt64() {
+		if p := powInt(x, n.Int64()); p != nil {
+			return z.Round(p, s, rounder)
+		}
+		if p := powInt(x, -n.Int64()); p != nil {
+			return z.Quo(NewDecInt64(1), p, s, rounder)
+		}
+	}
+	if !isInt {
+		if num, den := powRoot(x, y); num != nil {
+			return z.Quo(NewDec(num, 0), NewDec(den, 0), s, rounder)
+		}
+	}
+
+	ti, _ := integerPart(t)
+	digits := Scale(2)
+	if t.Sign() > 0 {
+		digits += Scale(ti.Int64() * 10 / 23)
+	}
+	return z.approx(s, sign, rounder, func(w Scale) *big.Int {
+		g := w + 1 + digits + yd + 4
+		l := lnFixed(ax, g)
+		n := expFixed(NewDec(l.Mul(l, y.Unscaled()), g+y.Scale()), w+1)
+		if sign < 0 {
+			n.Neg(n)
+		}
+		return n.Quo(n, bigInt[10])
+	})
+}
+
+// powSpecial sets z to x**y where at least one is special
+func (z *Dec) powSpecial(x, y *Dec, s Scale, rounder Rounder) *Dec {
+	one := NewDecInt64(1)
+	switch {
+	case y.Sign() == 0 && !y.IsNaN(), x.Cmp(one) == 0:
+		return z.Round(one, s, rounder)
+	case x.IsNaN() || y.IsNaN():
+		return z.SetNaN()
+	case y.IsInf():
+		c := new(Dec).Abs(x).Cmp(one)
+		switch {
+		case c == 0:
+			return z.Round(one, s, rounder)
+		case c*y.Sign() > 0:
+			return z.SetInf(false)
+		}
+		return z.SetUnscaled(bigInt[0]).SetScale(s)
+	case y.Sign() < 0:
+		// x is an infinity
+		return z.SetUnscaled(bigInt[0]).SetScale(s)
+	}
+	n, isInt := integerPart(y)
+	return z.SetInf(x.Sign() < 0 && isInt && n.Bit(0) == 1)
+}
+
+// integerPart returns the integer part of x, truncated towards zero, and
+// whether x is an integer
+func integerPart(x *Dec) (*big.Int, bool) {
+	if x.Scale() <= 0 {
+		return new(big.Int).Mul(x.Unscaled(), exp10(-x.Scale())), true
+	}
+	q, r := new(big.Int).QuoRem(x.Unscaled(), exp10(x.Scale()), new(big.Int))
+	return q, r.Sign() == 0
+}
+
+// powInt returns x**n, or nil if n is negative or the scale of the result
+// does not fit in a Scale
+func powInt(x *Dec, n int64) *Dec {
+	if n < 0 {
+		return nil
+	}
+	s := int64(x.Scale()) * n
+	if s != int64(Scale(s)) || (n != 0 && s/n != int64(x.Scale())) {
+		return nil
+	}
+	u := new(big.Int).Exp(x.Unscaled(), big.NewInt(n), nil)
+	return NewDec(u, Scale(s))
+}
+
+// powRoot returns the numerator and denominator of x**y if x is the power
+// of a rational whose exponent is the denominator of y, or nil otherwise.
+// x must be positive.
+func powRoot(x, y *Dec) (*big.Int, *big.Int) {
+	f := new(big.Rat).SetFrac(y.Unscaled(), exp10(y.Scale()))
+	xr := new(big.Rat).SetInt(fixed(x, 0))
+	if x.Scale() > 0 {
+		xr.SetFrac(x.Unscaled(), exp10(x.Scale()))
+	}
+	// the root of degree k of an integer m > 1 can only be an integer if
+	// k <= log2(m)
+	k := f.Denom()
+	if !f.Num().IsInt64() || !k.IsInt64() ||
+		tooFewBits(xr.Num(), k.Int64()) || tooFewBits(xr.Denom(), k.Int64()) {
+		return nil, nil
+	}
+	num, den := rootInt(xr.Num(), k.Int64()), rootInt(xr.Denom(), k.Int64())
+	if new(big.Int).Exp(num, k, nil).Cmp(xr.Num()) != 0 ||
+		new(big.Int).Exp(den, k, nil).Cmp(xr.Denom()) != 0 {
+		return nil, nil
+	}
+	p := f.Num().Int64()
+	if p < 0 {
+		num, den, p = den, num, -p
+	}
+	bp := big.NewInt(p)
+	return num.Exp(num, bp, nil), den.Exp(den, bp, nil)
+}
+
+func tooFewBits(m *big.Int, k int64) bool {
+	return m.Cmp(bigInt[1]) > 0 && k > int64(m.BitLen())
+}
+
+// rootInt returns the k-th root of m >= 0, rounded down
+func rootInt(m *big.Int, k int64) *big.Int {
+	if m.Sign() == 0 || k == 1 {
+		return new(big.Int).Set(m)
+	}
+	bk := big.NewInt(k)
+	bk1 := big.NewInt(k - 1)
+	// Newton's method from above the root decreases to it
+	r := new(big.Int).Lsh(bigInt[1], uint(m.BitLen())/uint(k)+1)
+	t := new(big.Int)
+	for {
+		// t = ((k-1)*r + m/r**(k-1)) / k
+		t.Exp(r, bk1, nil)
+		t.Quo(m, t)
+		t.Add(t, new(big.Int).Mul(r, bk1))
+		t.Quo(t, bk)
+		if t.Cmp(r) >= 0 {
+			return r
+		}
+		r.Set(t)
+	}
+}
+
+// powerOf10 returns e and true if x is 10**e
+func powerOf10(x *Dec) (int64, bool) {
+	u := new(big.Int).Set(x.Unscaled())
+	e := -int64(x.Scale())
+	r := new(big.Int)
+	for u.Cmp(bigInt[10]) >= 0 {
+		if u.QuoRem(u, bigInt[10], r); r.Sign() != 0 {
+			return 0, false
+		}
+		e++
+	}
+	return e, u.Cmp(bigInt[1]) == 0
+}
+
+// decExponent returns e such that 10**(e-1) <= |x| < 10**e for x != 0
+func decExponent(x *Dec) int64 {
+	return int64(len(new(big.Int).Abs(x.Unscaled()).String())) - int64(x.Scale())
+}
+
+// digitsOf returns the number of decimal digits of |n|
+func digitsOf(n int64) Scale {
+	d := Scale(1)
+	for ; n >= 10 || n <= -10; n /= 10 {
+		d++
+	}
+	return d
+}
+
+// approx sets z to the value approximated by f rounded to scale s, and
+// returns z. f(w) must return the value times 10**w with an error less than
+// mathGuardUlps, and sign is the sign of the value.
+func (z *Dec) approx(s Scale, sign int, rounder Rounder, f func(w Scale) *big.Int) *Dec {
+	d := Scale(mathGuardDigits)
+	if s+d < mathGuardDigits {
+		d = mathGuardDigits - s
+	}
+	for {
+		n := f(s + d)
+		// the boundaries are the multiples of 10**d for directed rounding
+		// and the half-way points for rounding to nearest
+		b := new(big.Int).Mul(bigInt[5], exp10(d-1))
+		r := new(big.Int).Mod(n, b)
+		if r.Cmp(big.NewInt(mathGuardUlps)) >= 0 &&
+			new(big.Int).Sub(b, r).Cmp(big.NewInt(mathGuardUlps)) >= 0 {
+			return z.roundFixed(n, s, d, n.Sign(), rounder)
+		}
+		if d >= mathMaxGuardDigits {
+			return z.roundFixed(n, s, d, sign, rounder)
+		}
+		d *= 2
+	}
+}
+
+// roundFixed sets z to n * 10**(-s-d) rounded to scale s using rounder and
+// returns z. If inexact is not zero, the value has additional digits beyond
+// n with that sign.
+func (z *Dec) roundFixed(n *big.Int, s, d Scale, inexact int, rounder Rounder) *Dec {
+	q, rem := new(big.Int).QuoRem(n, exp10(d), new(big.Int))
+	var zz *Dec
+	if rounder.UseRemainder() {
+		// the remainder is doubled to make room for the extra digits
+		rem.Lsh(rem, 1)
+		rem.Add(rem, big.NewInt(int64(inexact)))
+		zz = rounder.Round(new(Dec), NewDec(q, s), rem, new(big.Int).Lsh(exp10(d), 1))
+	} else {
+		zz = rounder.Round(new(Dec), NewDec(q, s), nil, nil)
+	}
+	if zz == nil {
+		return nil
+	}
+	return z.Set(zz)
+}
+
+// fixed returns x * 10**w truncated towards zero
+func fixed(x *Dec, w Scale) *big.Int {
+	if w >= x.Scale() {
+		return new(big.Int).Mul(x.Unscaled(), exp10(w-x.Scale()))
+	}
+	return new(big.Int).Quo(x.Unscaled(), exp10(x.Scale()-w))
+}
+
+// expFixed returns e**x * 10**w with an error less than mathGuardUlps
+func expFixed(x *Dec, w Scale) *big.Int {
+	xi, _ := integerPart(x)
+	if x.Sign() < 0 {
+		if xi.Cmp(big.NewInt(-3*int64(w)-3)) < 0 {
+			// e**x * 10**w < 1
+			return new(big.Int)
+		}
+		// the reciprocal of a value >= 1 has no more relative error
+		g := w + 2
+		n := expFixed(new(Dec).Neg(x), g)
+		return n.Quo(exp10(w+g), n)
+	}
+	// e**x has about x/ln(10) integer digits, which need to be computed
+	// on top of w
+	digits := Scale(xi.Int64()*10/23) + 1
+	// e**x = (e**(x/2**k))**(2**k) with x/2**k < 2**-10, each squaring
+	// doubles the relative error and each term of the series adds one
+	// unit of the last place
+	k := uint(xi.BitLen() + 10)
+	g := w + digits + Scale(k)*3/10 + 4
+	g += digitsOf(int64(g))
+	one := exp10(g)
+	r := fixed(x, g)
+	r.Rsh(r, k)
+
+	// Taylor series
+	sum := new(big.Int).Set(one)
+	term := new(big.Int).Set(one)
+	for i := int64(1); ; i++ {
+		term.Mul(term, r)
+		term.Quo(term, one)
+		term.Quo(term, big.NewInt(i))
+		if term.Sign() == 0 {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	for i := uint(0); i < k; i++ {
+		sum.Mul(sum, sum)
+		sum.Quo(sum, one)
+	}
+	return sum.Quo(sum, exp10(g-w))
+}
+
+// lnFixed returns ln(x) * 10**w for x > 0 with an error less than
+// mathGuardUlps
+func lnFixed(x *Dec, w Scale) *big.Int {
+	// x = m * 10**e with 0.1 <= m < 1, so ln(x) = ln(m) + e*ln(10)
+	e := decExponent(x)
+	g := w + 6 + digitsOf(int64(w)) + digitsOf(e)
+	m := NewDec(x.Unscaled(), Scale(int64(x.Scale())+e))
+	n := lnReduced(fixed(m, g), g)
+	if e != 0 {
+		n.Add(n, new(big.Int).Mul(big.NewInt(e), ln10Fixed(g)))
+	}
+	return n.Quo(n, exp10(g-w))
+}
+
+// ln10Fixed returns ln(10) * 10**w with an error less than mathGuardUlps
+func ln10Fixed(w Scale) *big.Int {
+	g := w + 6 + digitsOf(int64(w))
+	n := lnReduced(new(big.Int).Set(exp10(g-1)), g)
+	n.Neg(n)
+	return n.Quo(n, exp10(g-w))
+}
+
+// lnReduced returns ln(m) * 10**w for m * 10**(-w) with 0.1 <= m <= 1.
+// There are at most 8 square roots and about w/4 terms of the series, so the
+// error is less than 2**9 * w units of the last place.
+func lnReduced(m *big.Int, w Scale) *big.Int {
+	one := exp10(w)
+	// ln(m) = 2**k * ln(m**(1/2**k)) where the root is close to 1
+	limit := new(big.Int).Quo(one, big.NewInt(100))
+	k := uint(0)
+	a := new(big.Int).Set(m)
+	d := new(big.Int)
+	for d.Sub(one, a).CmpAbs(limit) > 0 {
+		a.Sqrt(a.Mul(a, one))
+		k++
+	}
+	// ln(a) = 2*atanh(t) = 2*(t + t**3/3 + t**5/5 + ...) with
+	// t = (a-1)/(a+1)
+	t := new(big.Int).Sub(a, one)
+	t.Mul(t, one)
+	t.Quo(t, new(big.Int).Add(a, one))
+	t2 := new(big.Int).Mul(t, t)
+	t2.Quo(t2, one)
+	sum := new(big.Int).Set(t)
+	term := new(big.Int).Set(t)
+	tmp := new(big.Int)
+	for i := int64(3); ; i += 2 {
+		term.Mul(term, t2)
+		term.Quo(term, one)
+		tmp.Quo(term, big.NewInt(i))
+		if tmp.Sign() == 0 {
+			break
+		}
+		sum.Add(sum, tmp)
+	}
+	return sum.Lsh(sum, k+1)
+}
@@ -0,0 +1,138 @@
+package dec
+
+import (
+	"testing"
+)
+
+type decFunScaled func(z, x, y *Dec, s Scaler, r Rounder) *Dec
+
+var decSqrt = func(z, x, y *Dec, s Scaler, r Rounder) *Dec { return z.Sqrt(x, s, r) }
+var decExp = func(z, x, y *Dec, s Scaler, r Rounder) *Dec { return z.Exp(x, s, r) }
+var decLn = func(z, x, y *Dec, s Scaler, r Rounder) *Dec { return z.Ln(x, s, r) }
+var decLog10 = func(z, x, y *Dec, s Scaler, r Rounder) *Dec { return z.Log10(x, s, r) }
+var decPow = func(z, x, y *Dec, s Scaler, r Rounder) *Dec { return z.Pow(x, y, s, r) }
+
+var decMathTests = []struct {
+	name string
+	f    decFunScaled
+	x, y string
+	s    Scale
+	r    Rounder
+	z    string // nil if ""
+}{
+	// known constants
+	{"Sqrt", decSqrt, "2", "", 100, RoundHalfEven,
+		"1.4142135623730950488016887242096980785696718753769480731766797379907324784621070388503875343276415727"},
+	{"Exp", decExp, "1", "", 100, RoundHalfEven,
+		"2.7182818284590452353602874713526624977572470936999595749669676277240766303535475945713821785251664274"},
+	{"Ln", decLn, "2", "", 100, RoundHalfEven,
+		"0.6931471805599453094172321214581765680755001343602552541206800094933936219696947156058633269964186875"},
+	{"Ln", decLn, "10", "", 100, RoundHalfEven,
+		"2.3025850929940456840179914546843642076011014886287729760333279009675726096773524802359972050895982983"},
+	{"Log10", decLog10, "2", "", 100, RoundHalfEven,
+		"0.3010299956639811952137388947244930267681898814621085413104274611271081892744245094869272521181861720"},
+	{"Pow", decPow, "2", "0.5", 60, RoundHalfEven,
+		"1.414213562373095048801688724209698078569671875376948073176680"},
+
+	// Sqrt
+	{"Sqrt", decSqrt, "0", "", 2, RoundExact, "0.00"},
+	{"Sqrt", decSqrt, "2.25", "", 1, RoundExact, "1.5"},
+	{"Sqrt", decSqrt, "2.25", "", 0, RoundHalfEven, "2"},
+	{"Sqrt", decSqrt, "2.25", "", 0, RoundHalfDown, "1"},
+	{"Sqrt", decSqrt, "2", "", 4, RoundExact, ""},
+	{"Sqrt", decSqrt, "2", "", 4, RoundDown, "1.4142"},
+	{"Sqrt", decSqrt, "2", "", 4, RoundUp, "1.4143"},
+	{"Sqrt", decSqrt, "0.0000000001", "", 2, RoundUp, "0.01"},
+	{"Sqrt", decSqrt, "0.0000000001", "", 2, RoundDown, "0.00"},
+	{"Sqrt", decSqrt, "1000000", "", -2, RoundExact, "1000"},
+	{"Sqrt", decSqrt, "-1", "", 2, RoundDown, "NaN"},
+	{"Sqrt", decSqrt, "Infinity", "", 2, RoundDown, "Infinity"},
+
+	// Exp
+	{"Exp", decExp, "0", "", 3, RoundExact, "1.000"},
+	{"Exp", decExp, "-1", "", 50, RoundHalfEven, "0.36787944117144232159552377016146086744581113103177"},
+	{"Exp", decExp, "100", "", 20, RoundHalfEven, "26881171418161354484126255515800135873611118.77374192241519160862"},
+	{"Exp", decExp, "-1000", "", 2, RoundUp, "0.01"},
+	{"Exp", decExp, "-1000", "", 2, RoundHalfUp, "0.00"},
+	{"Exp", decExp, "1", "", 2, RoundExact, ""},
+	{"Exp", decExp, "-Infinity", "", 2, RoundDown, "0.00"},
+
+	// Ln and Log10
+	{"Ln", decLn, "1", "", 2, RoundExact, "0.00"},
+	{"Ln", decLn, "0.000000000000000000005", "", 50, RoundHalfEven, "-46.74484904044085898977706121514546072009752990693571"},
+	{"Ln", decLn, "1.000000000000000000000000000001", "", 3, RoundCeil, "0.001"},
+	{"Ln", decLn, "0", "", 2, RoundDown, "-Infinity"},
+	{"Ln", decLn, "-2", "", 2, RoundDown, "NaN"},
+	{"Log10", decLog10, "12345.6789", "", 40, RoundHalfEven, "4.0915149771692704475183336230595472585151"},
+	{"Log10", decLog10, "1000", "", 2, RoundExact, "3.00"},
+	{"Log10", decLog10, "0.001", "", 0, RoundExact, "-3"},
+
+	// Pow
+	{"Pow", decPow, "1.05", "30", 60, RoundExact, "4.321942375150662009157288198886473341473378241062164306640625"},
+	{"Pow", decPow, "1.05", "30", 2, RoundHalfEven, "4.32"},
+	{"Pow", decPow, "-1.5", "7", 7, RoundExact, "-17.0859375"},
+	{"Pow", decPow, "2", "-10", 10, RoundExact, "0.0009765625"},
+	{"Pow", decPow, "3", "-1", 4, RoundHalfUp, "0.3333"},
+	{"Pow", decPow, "4", "0.5", 0, RoundDown, "2"},
+	{"Pow", decPow, "0.0081", "0.25", 2, RoundExact, "0.30"},
+	{"Pow", decPow, "8", "-1.5", 20, RoundHalfEven, "0.04419417382415922028"},
+	{"Pow", decPow, "1.0001", "12.5", 60, RoundHalfEven,
+		"1.001250719001622256251855659892138672784493633292889842987899"},
+	{"Pow", decPow, "2.5", "-3.3", 60, RoundHalfEven,
+		"0.048618098747671927981428279880413208573527911934602198190246"},
+	{"Pow", decPow, "-2", "0.5", 2, RoundDown, "NaN"},
+	{"Pow", decPow, "0", "-2", 2, RoundDown, "Infinity"},
+	{"Pow", decPow, "NaN", "0", 2, RoundDown, "1.00"},
+	{"Pow", decPow, "0.5", "Infinity", 2, RoundDown, "0.00"},
+	{"Pow", decPow, "-Infinity", "3", 2, RoundDown, "-Infinity"},
+
+	// results beyond 10**±6000
+	{"Exp", decExp, "10000000000000000000000000", "", 4, RoundHalfEven, "Infinity"},
+	{"Exp", decExp, "100000", "", 4, RoundHalfEven, "Infinity"},
+	{"Exp", decExp, "-10000000000000000000000000", "", 4, RoundUp, "0.0000"},
+	{"Exp", decExp, "-13816", "", 4, RoundUp, "0.0000"},
+	{"Pow", decPow, "2", "1000000000", 4, RoundHalfEven, "Infinity"},
+	{"Pow", decPow, "-2", "1000000001", 4, RoundHalfEven, "-Infinity"},
+	{"Pow", decPow, "2", "-1000000000", 4, RoundUp, "0.0000"},
+	{"Pow", decPow, "10", "6001", 0, RoundExact, "Infinity"},
+	{"Pow", decPow, "0.1", "6001", 0, RoundExact, "0"},
+	{"Pow", decPow, "1.0000001", "1000000000000", 0, RoundExact, "Infinity"},
+	{"Pow", decPow, "1", "100000000000000000000000", 2, RoundExact, "1.00"},
+	{"Pow", decPow, "-1", "100000000000000000000001", 2, RoundExact, "-1.00"},
+}
+
+func TestDecMathLargest(t *testing.T) {
+	// the largest results are computed
+	z := new(Dec).Exp(NewDecInt64(13815), Scale(0), RoundDown)
+	if s := z.String(); len(s) != 6000 || s[:16] != "6001606171895307" {
+		t.Errorf("Exp(13815) got %d digits %.16s", len(s), s)
+	}
+	z = new(Dec).Pow(NewDecInt64(10), NewDecInt64(6000), Scale(0), RoundExact)
+	if z.Cmp(new(Dec).SetUnscaled(exp10(6000))) != 0 {
+		t.Errorf("Pow(10, 6000) got %.16s", z)
+	}
+}
+
+func TestDecMath(t *testing.T) {
+	for i, test := range decMathTests {
+		x, _ := new(Dec).SetString(test.x)
+		y, _ := new(Dec).SetString(test.y)
+		z := test.f(new(Dec), x, y, test.s, test.r)
+		switch {
+		case z == nil && test.z != "":
+			t.Errorf("#%d %s(%s, %s) got nil; want %s", i, test.name, test.x, test.y, test.z)
+		case z != nil && z.String() != test.z:
+			t.Errorf("#%d %s(%s, %s) got %s; want %s", i, test.name, test.x, test.y, z, test.z)
+		}
+	}
+}
+
+func TestDecMathAlias(t *testing.T) {
+	x := NewDecInt64(16)
+	if x.Sqrt(x, Scale(0), RoundExact); x.String() != "4" {
+		t.Errorf("got %s; want 4", x)
+	}
+	if x.Pow(x, x, Scale(0), RoundExact); x.String() != "256" {
+		t.Errorf("got %s; want 256", x)
+	}
+}
@@ -0,0 +1,304 @@
+package dec
+
+// This file implements the text and binary representations of the
+// NUMERIC type of PostgreSQL.
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// The values of the sign field of the binary representation
+const (
+	pgNumericPos  = 0x0000
+	pgNumericNeg  = 0x4000
+	pgNumericNaN  = 0xC000
+	pgNumericPinf = 0xD000
+	pgNumericNinf = 0xF000
+)
+
+// pgNumericBase is the base of the digits of the binary representation,
+// each of which holds pgNumericDecDigits decimal digits
+const pgNumericBase = 10000
+const pgNumericDecDigits = 4
+
+// pgNumericWord is the number of base 10000 digits that fit in a uint64
+// with room to multiply by the base
+const pgNumericWord = 4
+
+var pgNumericWordBase = new(big.Int).SetUint64(1e16)
+
+// maxUint64Digits is the number of decimal digits that always fit in
+// a uint64
+const maxUint64Digits = 19
+
+// AppendString appends the same representation of x as String to buf
+// and returns the extended buffer. Values that fit in an int64 are
+// formatted without allocating.
+func (x *Dec) AppendString(buf []byte) []byte {
+	switch {
+	case x.IsNaN():
+		return append(buf, "NaN"...)
+	case x.IsInf() && x.Sign() < 0:
+		return append(buf, "-Infinity"...)
+	case x.IsInf():
+		return append(buf, "Infinity"...)
+	}
+	var tmp [24]byte
+	var s []byte
+	if x.unscaled.IsInt64() {
+		s = strconv.AppendInt(tmp[:0], x.unscaled.Int64(), 10)
+	} else {
+		s = x.unscaled.Append(tmp[:0], 10)
+	}
+	scale := x.scale
+	if scale <= 0 {
+		buf = append(buf, s...)
+		if scale != 0 && x.unscaled.Sign() != 0 {
+			buf = appendZeros(buf, -scale)
+		}
+		return buf
+	}
+	var negbit Scale
+	if x.unscaled.Sign() < 0 {
+		negbit = 1
+	}
+	lens := Scale(len(s))
+	if lens-negbit <= scale {
+		if negbit == 1 {
+			buf = append(buf, '-')
+		}
+		buf = append(buf, '0', '.')
+		buf = appendZeros(buf, scale-lens+negbit)
+		return append(buf, s[negbit:]...)
+	}
+	buf = append(buf, s[:lens-scale]...)
+	buf = append(buf, '.')
+	return append(buf, s[lens-scale:]...)
+}
+
+// SetStringBytes is the same as SetString, but reads from a byte slice
+// such as a NUMERIC column in the text format. The digits are
+// accumulated in machine words instead of being copied to a string.
+func (z *Dec) SetStringBytes(b []byte) (*Dec, bool) {
+	if len(b) != 0 && !isDigitOrPoint(b[len(b)-1]) {
+		if z.setSpecial(string(b)) {
+			return z, true
+		}
+		return nil, false
+	}
+	i := 0
+	neg := false
+	if len(b) > 0 && (b[0] == '+' || b[0] == '-') {
+		neg = b[0] == '-'
+		i++
+	}
+	var w big.Int
+	z.unscaled.SetUint64(0)
+	dp, ndigits := -1, 0
+	var word uint64
+	n := 0
+	for ; i < len(b); i++ {
+		c := b[i]
+		if c == '.' && dp < 0 {
+			dp = ndigits
+			continue
+		}
+		if c < '0' || c > '9' {
+			return nil, false
+		}
+		word = word*10 + uint64(c-'0')
+		n++
+		ndigits++
+		if n == maxUint64Digits {
+			z.unscaled.Mul(&z.unscaled, exp10(Scale(n)))
+			z.unscaled.Add(&z.unscaled, w.SetUint64(word))
+			word, n = 0, 0
+		}
+	}
+	if ndigits == 0 {
+		return nil, false
+	}
+	if n != 0 {
+		z.unscaled.Mul(&z.unscaled, exp10(Scale(n)))
+		z.unscaled.Add(&z.unscaled, w.SetUint64(word))
+	}
+	if neg {
+		z.unscaled.Neg(&z.unscaled)
+	}
+	if dp >= 0 {
+		z.scale = Scale(ndigits - dp)
+	} else {
+		z.scale = 0
+	}
+	z.form = finite
+	return z, true
+}
+
+func isDigitOrPoint(c byte) bool {
+	return c == '.' || (c >= '0' && c <= '9')
+}
+
+// AppendPgNumeric appends the binary representation of x used by the
+// NUMERIC type of PostgreSQL to buf and returns the extended buffer.
+// The representation is the number of digits, the weight of the first
+// digit, the sign and the display scale followed by the digits in base
+// 10000. A negative scale is written as a scale of 0. An error is
+// returned if the scale or weight do not fit in the representation.
+// The special values have no digits and a sign that identifies them.
+func (x *Dec) AppendPgNumeric(buf []byte) ([]byte, error) {
+	if x.isSpecial() {
+		sign := uint16(pgNumericNaN)
+		if x.IsInf() && x.Sign() > 0 {
+			sign = pgNumericPinf
+		} else if x.IsInf() {
+			sign = pgNumericNinf
+		}
+		buf = appendUint16(buf, 0)
+		buf = appendUint16(buf, 0)
+		buf = appendUint16(buf, sign)
+		return appendUint16(buf, 0), nil
+	}
+	u := &x.unscaled
+	dscale := x.scale
+	if dscale < 0 {
+		u = new(big.Int).Mul(u, exp10(-dscale))
+		dscale = 0
+	}
+	if dscale > math.MaxInt16 {
+		return buf, fmt.Errorf("Dec.AppendPgNumeric: scale %d too large", dscale)
+	}
+	// the fraction is padded to a whole number of digits
+	pad := (pgNumericDecDigits - dscale%pgNumericDecDigits) % pgNumericDecDigits
+	if pad != 0 {
+		u = new(big.Int).Mul(u, exp10(pad))
+	}
+	fracDigits := int((dscale + pad) / pgNumericDecDigits)
+
+	// digits are collected least significant first
+	var tmp [40]uint16
+	digits := tmp[:0]
+	if u.IsInt64() {
+		v := u.Int64()
+		w := uint64(v)
+		if v < 0 {
+			w = -w
+		}
+		for w != 0 {
+			digits = append(digits, uint16(w%pgNumericBase))
+			w /= pgNumericBase
+		}
+	} else {
+		q := new(big.Int).Abs(u)
+		r := new(big.Int)
+		for q.Sign() != 0 {
+			q.QuoRem(q, pgNumericWordBase, r)
+			w := r.Uint64()
+			for i := 0; i < pgNumericWord; i++ {
+				digits = append(digits, uint16(w%pgNumericBase))
+				w /= pgNumericBase
+			}
+		}
+	}
+	for len(digits) != 0 && digits[len(digits)-1] == 0 {
+		digits = digits[:len(digits)-1]
+	}
+	weight := len(digits) - 1 - fracDigits
+	for len(digits) != 0 && digits[0] == 0 {
+		digits = digits[1:]
+	}
+
+	sign := uint16(pgNumericPos)
+	if u.Sign() < 0 {
+		sign = pgNumericNeg
+	}
+	if len(digits) == 0 {
+		weight = 0
+	}
+	if weight > math.MaxInt16 || weight < math.MinInt16 {
+		return buf, fmt.Errorf("Dec.AppendPgNumeric: weight %d out of range", weight)
+	}
+
+	buf = appendUint16(buf, uint16(len(digits)))
+	buf = appendUint16(buf, uint16(int16(weight)))
+	buf = appendUint16(buf, sign)
+	buf = appendUint16(buf, uint16(dscale))
+	for i := len(digits) - 1; i >= 0; i-- {
+		buf = appendUint16(buf, digits[i])
+	}
+	return buf, nil
+}
+
+func appendUint16(buf []byte, v uint16) []byte {
+	return append(buf, byte(v>>8), byte(v))
+}
+
+// SetPgNumeric sets z to the value of the binary representation of
+// the NUMERIC type of PostgreSQL in b and returns z. The scale of z is
+// the display scale, digits beyond it are truncated as PostgreSQL
+// does. If b is not valid the value of z is undefined but the returned
+// value is nil.
+func (z *Dec) SetPgNumeric(b []byte) (*Dec, error) {
+	if len(b) < 8 {
+		return nil, fmt.Errorf("Dec.SetPgNumeric: %d bytes is too short", len(b))
+	}
+	ndigits := int(binary.BigEndian.Uint16(b[0:]))
+	weight := int(int16(binary.BigEndian.Uint16(b[2:])))
+	sign := binary.BigEndian.Uint16(b[4:])
+	dscale := int(binary.BigEndian.Uint16(b[6:]))
+	if len(b) != 8+2*ndigits {
+		return nil, fmt.Errorf("Dec.SetPgNumeric: %d bytes for %d digits", len(b), ndigits)
+	}
+	switch sign {
+	case pgNumericPos, pgNumericNeg:
+	case pgNumericNaN:
+		return z.SetNaN(), nil
+	case pgNumericPinf, pgNumericNinf:
+		return z.SetInf(sign == pgNumericNinf), nil
+	default:
+		return nil, fmt.Errorf("Dec.SetPgNumeric: invalid sign %#04x", sign)
+	}
+	if dscale > math.MaxInt16 {
+		return nil, fmt.Errorf("Dec.SetPgNumeric: invalid scale %d", dscale)
+	}
+
+	var w big.Int
+	z.unscaled.SetUint64(0)
+	var word uint64
+	n := 0
+	for i := 0; i < ndigits; i++ {
+		d := binary.BigEndian.Uint16(b[8+2*i:])
+		if d >= pgNumericBase {
+			return nil, fmt.Errorf("Dec.SetPgNumeric: invalid digit %d", d)
+		}
+		word = word*pgNumericBase + uint64(d)
+		n++
+		if n == pgNumericWord {
+			z.unscaled.Mul(&z.unscaled, pgNumericWordBase)
+			z.unscaled.Add(&z.unscaled, w.SetUint64(word))
+			word, n = 0, 0
+		}
+	}
+	if n != 0 {
+		z.unscaled.Mul(&z.unscaled, exp10(Scale(n*pgNumericDecDigits)))
+		z.unscaled.Add(&z.unscaled, w.SetUint64(word))
+	}
+
+	// the digits are an integer times 10000**(weight-ndigits+1)
+	e := pgNumericDecDigits*(weight-ndigits+1) + dscale
+	switch {
+	case e > 0:
+		z.unscaled.Mul(&z.unscaled, exp10(Scale(e)))
+	case e < 0:
+		z.unscaled.Quo(&z.unscaled, exp10(Scale(-e)))
+	}
+	if sign == pgNumericNeg {
+		z.unscaled.Neg(&z.unscaled)
+	}
+	z.scale = Scale(dscale)
+	z.form = finite
+	return z, nil
+}
@@ -0,0 +1,157 @@
+package dec
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func pgNumeric(weight int16, sign uint16, dscale uint16, digits ...uint16) []byte {
+	buf := appendUint16(nil, uint16(len(digits)))
+	buf = appendUint16(buf, uint16(weight))
+	buf = appendUint16(buf, sign)
+	buf = appendUint16(buf, dscale)
+	for _, d := range digits {
+		buf = appendUint16(buf, d)
+	}
+	return buf
+}
+
+var decPgNumericTests = []struct {
+	in  string
+	out []byte
+}{
+	{"0", pgNumeric(0, pgNumericPos, 0)},
+	{"0.00", pgNumeric(0, pgNumericPos, 2)},
+	{"1", pgNumeric(0, pgNumericPos, 0, 1)},
+	{"-1", pgNumeric(0, pgNumericNeg, 0, 1)},
+	{"10000", pgNumeric(1, pgNumericPos, 0, 1)},
+	{"12345.678", pgNumeric(1, pgNumericPos, 3, 1, 2345, 6780)},
+	{"-0.0001", pgNumeric(-1, pgNumericNeg, 4, 1)},
+	{"0.00001", pgNumeric(-2, pgNumericPos, 5, 1000)},
+	{"1.50", pgNumeric(0, pgNumericPos, 2, 1, 5000)},
+	{"NaN", pgNumeric(0, pgNumericNaN, 0)},
+	{"Infinity", pgNumeric(0, pgNumericPinf, 0)},
+	{"-Infinity", pgNumeric(0, pgNumericNinf, 0)},
+	{"123456789012345678901234567890.12", pgNumeric(7, pgNumericPos, 2,
+		12, 3456, 7890, 1234, 5678, 9012, 3456, 7890, 1200)},
+}
+
+func TestDecPgNumeric(t *testing.T) {
+	for i, test := range decPgNumericTests {
+		x, _ := new(Dec).SetString(test.in)
+		out, err := x.AppendPgNumeric(nil)
+		if err != nil {
+			t.Errorf("#%d %s: %v", i, test.in, err)
+			continue
+		}
+		if !bytes.Equal(out, test.out) {
+			t.Errorf("#%d %s: got %x; want %x", i, test.in, out, test.out)
+		}
+		z, err := new(Dec).SetPgNumeric(test.out)
+		if err != nil {
+			t.Errorf("#%d %s: %v", i, test.in, err)
+			continue
+		}
+		if z.String() != test.in {
+			t.Errorf("#%d got %s; want %s", i, z, test.in)
+		}
+	}
+}
+
+func TestDecPgNumericNegativeScale(t *testing.T) {
+	x := NewDec(big.NewInt(-12), -5)
+	out, err := x.AppendPgNumeric(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := pgNumeric(1, pgNumericNeg, 0, 120); !bytes.Equal(out, expected) {
+		t.Errorf("got %x; want %x", out, expected)
+	}
+}
+
+func TestDecPgNumericTruncates(t *testing.T) {
+	z, err := new(Dec).SetPgNumeric(pgNumeric(0, pgNumericNeg, 2, 1, 2345))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if z.String() != "-1.23" {
+		t.Errorf("got %s; want -1.23", z)
+	}
+}
+
+func TestDecPgNumericInvalid(t *testing.T) {
+	for i, in := range [][]byte{
+		nil,
+		pgNumeric(0, pgNumericPos, 0)[:7],
+		pgNumeric(0, pgNumericPos, 0, 1)[:9],
+		pgNumeric(0, 0x1234, 0),
+		pgNumeric(0, pgNumericPos, 0, 10000),
+	} {
+		if z, err := new(Dec).SetPgNumeric(in); err == nil || z != nil {
+			t.Errorf("#%d %x: got %v, %v; want error", i, in, z, err)
+		}
+	}
+}
+
+func TestDecPgNumericRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	max := new(big.Int).Lsh(big.NewInt(1), 256)
+	for i := 0; i < 1000; i++ {
+		x := NewDec(new(big.Int).Rand(r, max), Scale(r.Int31n(64)))
+		if r.Intn(2) == 0 {
+			x.Neg(x)
+		}
+		out, err := x.AppendPgNumeric(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		z, err := new(Dec).SetPgNumeric(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if z.Cmp(x) != 0 || z.Scale() != x.Scale() {
+			t.Fatalf("got %s; want %s", z, x)
+		}
+	}
+}
+
+func TestDecSetStringBytes(t *testing.T) {
+	tmp := new(Dec)
+	for i, test := range decStringTests {
+		if test.scale < 0 {
+			continue
+		}
+		tmp.Set(NewDecInt64(1234567890).SetScale(123))
+		z, ok := tmp.SetStringBytes([]byte(test.in))
+		if ok != test.ok {
+			t.Errorf("#%d (input '%s') ok incorrect (should be %t)", i, test.in, test.ok)
+			continue
+		}
+		if !ok {
+			if z != nil {
+				t.Errorf("#%d (input '%s') z != nil", i, test.in)
+			}
+			continue
+		}
+		expected := NewDecInt64(test.val).SetScale(test.scale)
+		if z.Cmp(expected) != 0 || z.Scale() != expected.Scale() {
+			t.Errorf("#%d (input '%s') got: %s want: %s", i, test.in, z, expected)
+		}
+	}
+
+	const long = "-123456789012345678901234567890.123456789012345678901234567890"
+	z, ok := new(Dec).SetStringBytes([]byte(long))
+	if !ok || z.String() != long {
+		t.Errorf("got %s; want %s", z, long)
+	}
+}
+
+func TestDecAppendString(t *testing.T) {
+	x := NewDec(new(big.Int).Lsh(big.NewInt(-1), 100), 20)
+	buf := x.AppendString([]byte("x="))
+	if string(buf) != "x=-12676506002.28229401496703205376" {
+		t.Errorf("got %s", buf)
+	}
+}
@@ -0,0 +1,84 @@
+package sillyquill_gen
+
+import "bufio"
+import "fmt"
+import "io"
+import "os"
+import "path/filepath"
+import "sort"
+
+// isGeneratedFile checks if the first line of the file is the
+// header written on every generated file
+func isGeneratedFile(filename string) (bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return line == generatedCodeHeader+"\n", nil
+}
+
+// CheckGenerated compares the generated files with the files in
+// outputDir and writes a unified diff of each difference to w.
+// Generated files in outputDir that are not in generated, for
+// example of a table that was dropped or excluded, are reported
+// as stale. It returns true if outputDir is up to date.
+func CheckGenerated(outputDir string, generated map[string][]byte, w io.Writer) (bool, error) {
+	upToDate := true
+
+	var filenames []string
+	for filename, _ := range generated {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		fullPath := filepath.Join(outputDir, filename)
+		existing, err := os.ReadFile(fullPath)
+		aName := fullPath
+		if os.IsNotExist(err) {
+			aName = "/dev/null"
+		} else if err != nil {
+			return false, err
+		}
+
+		diff := unifiedDiff(aName, fullPath, existing, generated[filename])
+		if diff != "" {
+			upToDate = false
+			fmt.Fprint(w, diff)
+		}
+	}
+
+	existingFiles, err := filepath.Glob(filepath.Join(outputDir, "*.go"))
+	if err != nil {
+		return false, err
+	}
+	sort.Strings(existingFiles)
+	for _, fullPath := range existingFiles {
+		if _, ok := generated[filepath.Base(fullPath)]; ok {
+			continue
+		}
+		isGenerated, err := isGeneratedFile(fullPath)
+		if err != nil {
+			return false, err
+		}
+		if !isGenerated {
+			continue
+		}
+
+		upToDate = false
+		existing, err := os.ReadFile(fullPath)
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintf(w, "Stale generated file %q\n", fullPath)
+		fmt.Fprint(w, unifiedDiff(fullPath, "/dev/null", existing, nil))
+	}
+
+	return upToDate, nil
+}
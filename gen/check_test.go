@@ -0,0 +1,104 @@
+package sillyquill_gen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n")
+	b := []byte("1\n2\n3\nfour\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n")
+	const expected = `--- a
++++ b
+@@ -1,7 +1,7 @@
+ 1
+ 2
+ 3
+-4
++four
+ 5
+ 6
+ 7
+@@ -13,3 +13,4 @@
+ 13
+ 14
+ 15
++16
+`
+	actual := unifiedDiff("a", "b", a, b)
+	if actual != expected {
+		t.Errorf("got\n%s\nwant\n%s", actual, expected)
+	}
+
+	if v := unifiedDiff("a", "b", a, a); v != "" {
+		t.Errorf("got %q for identical input", v)
+	}
+
+	const created = `--- /dev/null
++++ b
+@@ -0,0 +1,2 @@
++x
++y
+`
+	actual = unifiedDiff("/dev/null", "b", nil, []byte("x\ny\n"))
+	if actual != created {
+		t.Errorf("got\n%s\nwant\n%s", actual, created)
+	}
+}
+
+func TestCheckGenerated(t *testing.T) {
+	dir := t.TempDir()
+	current := []byte(generatedCodeHeader + "\n\npackage dal\n")
+	generated := map[string][]byte{
+		"trucks.go": current,
+	}
+
+	err := os.WriteFile(filepath.Join(dir, "trucks.go"), current, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "handwritten.go"), []byte("package dal\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	upToDate, err := CheckGenerated(dir, generated, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !upToDate || out.Len() != 0 {
+		t.Fatalf("got %v %q for up to date directory", upToDate, out.String())
+	}
+
+	//A file of a dropped table is stale
+	err = os.WriteFile(filepath.Join(dir, "cars.go"), current, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//A table that is new has no file yet
+	generated["wheels.go"] = current
+
+	upToDate, err = CheckGenerated(dir, generated, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upToDate {
+		t.Fatal("expected drift to be detected")
+	}
+	report := out.String()
+	for _, expected := range []string{
+		"--- /dev/null\n+++ " + filepath.Join(dir, "wheels.go"),
+		"Stale generated file " + `"` + filepath.Join(dir, "cars.go") + `"`,
+	} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("report does not contain %q:\n%s", expected, report)
+		}
+	}
+	if strings.Contains(report, "handwritten.go") {
+		t.Errorf("file without generated header reported:\n%s", report)
+	}
+}
@@ -0,0 +1,136 @@
+package sillyquill_gen
+
+import "fmt"
+import "strings"
+import "io"
+import "text/template"
+import "github.com/spiceworks/spicelog"
+
+type ColumnType struct {
+	InterfaceName         string
+	ListTypeName          string
+	Defns                 []ColumnTypeDefn
+	Parent                *ColumnizedStruct
+	AllColumnsName        string
+	PrimaryKeyColumnsName string
+	Template              *template.Template
+}
+
+type ColumnTypeDefn struct {
+	ColumnName   string
+	TypeName     string
+	InstanceName string
+	FieldName    string
+	Nullable     bool
+	Pointer      bool
+	NullField    string
+	Index        int
+	DataType     SqlDataType
+	FieldType    string
+	//ValueType is the type of the values of the column returned
+	//by ValueOf, which is the field type without a pointer or the
+	//type of the value held by a Null type
+	ValueType string
+	//NullType is the Null type of the runtime holding the value when
+	//NullField is set, which is also behind a pointer when Pointer is
+	NullType string
+}
+
+func privatizeTypeName(v string) string {
+	result := strings.ToLower(string(v[0]))
+	result = result + v[1:]
+	return result
+}
+
+func (this *ColumnType) Suffix() string {
+	return "_columns"
+}
+
+func NewColumnType(that *ColumnizedStruct) *ColumnType {
+	this := new(ColumnType)
+	this.Parent = that
+	this.InterfaceName = fmt.Sprintf("%sColumn", that.SingularModelName)
+	this.ListTypeName = fmt.Sprintf("%sColumnList", that.SingularModelName)
+	this.AllColumnsName = fmt.Sprintf("%sColumns", that.PluralModelName)
+	this.PrimaryKeyColumnsName = fmt.Sprintf("%sPrimaryKeyColumns", that.PluralModelName)
+	spicelog.Infof("Model %q column type %q",
+		that.SingularModelName,
+		this.InterfaceName)
+	for i, column := range that.Columns {
+		field := that.Fields[i]
+
+		defn := ColumnTypeDefn{}
+		defn.Index = i
+		defn.DataType = column.DataType()
+		defn.ColumnName = column.Name()
+		defn.FieldName = field.Name
+		defn.TypeName = fmt.Sprintf("%sColumn%s",
+			privatizeTypeName(that.SingularModelName),
+			field.Name)
+
+		defn.InstanceName = fmt.Sprintf("%s.%s",
+			that.PluralModelName,
+			field.Name,
+		)
+		defn.Nullable = column.Nullable()
+		defn.Pointer = field.Pointer
+		defn.NullField = field.NullField
+		defn.FieldType = field.DataType
+		defn.ValueType = strings.TrimPrefix(field.DataType, "*")
+		if field.NullField != "" {
+			defn.NullType = defn.ValueType
+			defn.ValueType = field.NullValueType
+		}
+
+		this.Defns = append(this.Defns, defn)
+	}
+
+	return this
+}
+
+// NullExpr is the expression that is true when the field of the model
+// m is NULL, or an empty string when the column is not nullable. It is
+// in parentheses when it is made of more than one operand.
+func (this ColumnTypeDefn) NullExpr() string {
+	field := "m." + this.FieldName
+	switch {
+	case this.Pointer && this.NullField != "":
+		return fmt.Sprintf("(%s == nil || !%s.Valid)", field, field)
+	case this.Pointer:
+		return field + " == nil"
+	case this.NullField != "":
+		return fmt.Sprintf("!%s.Valid", field)
+	}
+	return ""
+}
+
+// ValueExpr is the expression of type ValueType holding the value of
+// the field of the model m when it is not NULL
+func (this ColumnTypeDefn) ValueExpr() string {
+	field := "m." + this.FieldName
+	switch {
+	case this.NullField != "":
+		return field + "." + this.NullField
+	case this.Pointer:
+		return "*" + field
+	}
+	return field
+}
+
+func (this *ColumnType) Imports() []string {
+	//The value types of the columns are the types of the fields
+	return this.Parent.Imports()
+}
+
+func (this *ColumnType) ColumnTypeInstanceByFieldName(fieldName string) string {
+	for _, defn := range this.Defns {
+		if defn.FieldName == fieldName {
+			return defn.InstanceName
+		}
+	}
+	panic(fieldName)
+}
+
+func (this *ColumnType) Emit(w io.Writer) error {
+	return this.Template.Execute(w, this)
+}
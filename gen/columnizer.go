@@ -0,0 +1,292 @@
+package sillyquill_gen
+
+import "fmt"
+import "github.com/spiceworks/spicelog"
+import "reflect"
+import "bytes"
+import "time"
+import "io"
+import "text/template"
+import "github.com/hydrogen18/sillyquill/rt"
+
+type CodeEmitter interface {
+	Emit(io.Writer) error
+	Imports() []string
+	Suffix() string
+}
+
+type ColumnizedField struct {
+	DataTypeDefn []interface{}
+	Name         string
+	ColumnName   string
+	DataType     string
+	SqlType      SqlDataType
+	Pointer      bool
+	Nullable     bool
+	Tag          string
+	JSONName     string
+	//NullField is the field holding the value when DataType is
+	//one of the Null types of the runtime or a pointer to one,
+	//NullValueType is its type
+	NullField     string
+	NullValueType string
+	//Precision and Scale are the declared limits of a NUMERIC
+	//column, Precision is zero when there are none
+	Precision int
+	Scale     int
+}
+
+type ColumnizedStruct struct {
+	PluralModelName   string
+	SingularModelName string
+	ListTypeName      string
+	Fields            []ColumnizedField
+	Columns           []Column
+	PrimaryKey        []ColumnizedField
+	Unique            []ColumnizedField
+	PreferredUnique   *ColumnizedField
+	UpdatedAt         *ColumnizedField
+	CreatedAt         *ColumnizedField
+	TableName         string
+	ExcludedTag       string
+	JSONNulls         bool
+	JSONNumbers       bool
+	Audit             bool
+	AuditTable        string
+	NumericRounder    string
+
+	TheColumnType *ColumnType
+	Template      *template.Template
+}
+
+func (this ColumnizedField) IsTimestamp() bool {
+	return this.SqlType == SqlTimestamp
+}
+
+func (this ColumnizedField) IsNumeric() bool {
+	return this.SqlType == SqlNumeric
+}
+
+func (this *ColumnizedStruct) Suffix() string {
+	return ""
+}
+
+func NewColumnizedStruct(t Table,
+	tableNameToStructNames func(string) (string, string),
+	columnNameToFieldName func(string) string,
+	columnnToDataType func(Column) []interface{}) (*ColumnizedStruct, error) {
+	this := new(ColumnizedStruct)
+	this.TableName = t.Name()
+
+	this.PluralModelName, this.SingularModelName = tableNameToStructNames(t.Name())
+	this.ListTypeName = fmt.Sprintf("%sList", this.SingularModelName)
+
+	spicelog.Infof("Table %q Plural %q Singular %q",
+		t.Name(),
+		this.PluralModelName,
+		this.SingularModelName)
+	var err error
+	this.Columns, err = t.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	primaryKey, err := t.PrimaryKey()
+	if err != nil {
+		return nil, err
+	}
+	primaryKeys := make(map[string]int)
+	for _, v := range primaryKey {
+		primaryKeys[v] = 0
+	}
+
+	unique, err := t.Unique()
+	if err != nil {
+		return nil, err
+	}
+	uniques := make(map[string]int)
+	for _, v := range unique {
+		uniques[v] = 0
+	}
+
+	for _, column := range this.Columns {
+		field := ColumnizedField{}
+
+		field.Name = columnNameToFieldName(column.Name())
+		field.ColumnName = column.Name()
+		field.DataTypeDefn = columnnToDataType(column)
+		field.SqlType = column.DataType()
+
+		field.DataType, err = dataTypeToString(field.DataTypeDefn)
+		if err != nil {
+			return nil, err
+		}
+
+		field.Nullable = column.Nullable()
+		field.Pointer = field.DataTypeDefn[0] == reflect.Ptr
+		if v, ok := column.(numericColumn); ok && field.SqlType == SqlNumeric {
+			field.Precision, field.Scale = v.NumericPrecision()
+		}
+		//A NullNumeric can also be behind a pointer
+		field.NullField, field.NullValueType = nullValueField(field.DataTypeDefn[len(field.DataTypeDefn)-1])
+
+		this.Fields = append(this.Fields, field)
+		spicelog.Infof("Table %q Column %q Field %q",
+			t.Name(),
+			column.Name(),
+			field.Name)
+
+		_, ok := uniques[column.Name()]
+		if ok {
+			this.Unique = append(this.Unique, field)
+		}
+
+		_, ok = primaryKeys[column.Name()]
+		if ok {
+			this.PrimaryKey = append(this.PrimaryKey, field)
+		}
+
+		if column.IsCreationTimestamp() {
+			this.CreatedAt = &field
+		}
+
+		if column.IsUpdateTimestamp() {
+			this.UpdatedAt = &field
+		}
+	}
+
+	if len(this.Unique) != 0 {
+		for _, v := range this.Unique {
+			if v.SqlType == SqlInt {
+				this.PreferredUnique = &v
+				break
+			}
+		}
+
+		if this.PreferredUnique == nil {
+			this.PreferredUnique = &this.Unique[0]
+		}
+
+		spicelog.Infof("Preferred unique for %q is %q",
+			t.Name(),
+			this.PreferredUnique.Name)
+	}
+
+	return this, nil
+}
+
+// nullValueField returns the field holding the value of one of the
+// Null types of the runtime and its type, or empty strings
+func nullValueField(prototype interface{}) (string, string) {
+	switch prototype.(type) {
+	case sillyquill_rt.NullInt16:
+		return "Int16", "int16"
+	case sillyquill_rt.NullInt32:
+		return "Int32", "int32"
+	case sillyquill_rt.NullInt64:
+		return "Int64", "int64"
+	case sillyquill_rt.NullBool:
+		return "Bool", "bool"
+	case sillyquill_rt.NullTime:
+		return "Time", "time.Time"
+	case sillyquill_rt.NullString:
+		return "String", "string"
+	case sillyquill_rt.NullFloat32:
+		return "Float32", "float32"
+	case sillyquill_rt.NullFloat64:
+		return "Float64", "float64"
+	case sillyquill_rt.NullBytes:
+		return "Bytes", "[]byte"
+	case sillyquill_rt.NullNumeric:
+		return "Numeric", "sillyquill_rt.Numeric"
+	}
+	return "", ""
+}
+
+func dataTypeToString(dt []interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	rk, ok := dt[0].(reflect.Kind)
+	if !ok {
+		return "", fmt.Errorf("First element not %T", rk)
+	}
+	i := 1
+
+	if rk == reflect.Ptr {
+		fmt.Fprintf(buf, "*")
+
+		rk, ok = dt[i].(reflect.Kind)
+		i++
+		if !ok {
+			return "", fmt.Errorf("First element not %T", rk)
+		}
+	}
+
+	switch rk {
+	case reflect.Int32,
+		reflect.Int64,
+		reflect.Bool,
+		reflect.String,
+		reflect.Float64,
+		reflect.Int16,
+		reflect.Float32:
+		fmt.Fprintf(buf, "%v", rk)
+	case reflect.Struct:
+		fmt.Fprintf(buf, "%T", dt[i])
+		i++
+	case reflect.Slice:
+		fmt.Fprintf(buf, "[]%v", dt[i])
+		i++
+	default:
+		return "", fmt.Errorf("Prototype not convertable %v", dt)
+	}
+
+	return buf.String(), nil
+
+}
+
+func (this *ColumnizedStruct) Imports() []string {
+
+	var result []string
+	result = append(result, "bytes")
+	result = append(result, "fmt")
+	result = append(result, "database/sql")
+	result = append(result, "context")
+	result = append(result, "github.com/hydrogen18/sillyquill/rt")
+	for _, field := range this.Fields {
+		if field.Precision != 0 {
+			result = append(result, "github.com/hydrogen18/sillyquill/dec")
+		}
+		var i int
+		kind, ok := field.DataTypeDefn[i].(reflect.Kind)
+		i++
+		if ok {
+
+			if kind == reflect.Ptr {
+				kind, ok = field.DataTypeDefn[i].(reflect.Kind)
+				i++
+				if !ok {
+					continue
+				}
+			}
+
+			if kind == reflect.Struct {
+				m := field.DataTypeDefn[i]
+				i++
+				switch m.(type) {
+				case time.Time, sillyquill_rt.NullTime:
+					result = append(result, "time")
+				case sillyquill_rt.Numeric:
+					result = append(result, "github.com/hydrogen18/sillyquill/rt")
+				}
+
+			}
+
+		}
+	}
+
+	return result
+}
+
+func (this *ColumnizedStruct) Emit(w io.Writer) error {
+	return this.Template.Execute(w, this)
+}
@@ -0,0 +1,814 @@
+package sillyquill_gen
+
+import "fmt"
+import "os"
+import "strconv"
+import "strings"
+import "unicode"
+
+// DDLAdapter reads the tables from a file of SQL DDL statements,
+// such as the output of pg_dump --schema-only. The statements
+// CREATE TABLE, CREATE TYPE ... AS ENUM, CREATE DOMAIN and
+// ALTER TABLE ... ADD CONSTRAINT are understood, anything else
+// is skipped.
+type DDLAdapter struct {
+	File        string
+	TableSchema string
+}
+
+func (this *DDLAdapter) Tables() ([]Table, error) {
+	data, err := os.ReadFile(this.File)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := ParseDDL(string(data), this.TableSchema)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing %q:%v", this.File, err)
+	}
+
+	var results []Table
+	for _, t := range tables {
+		results = append(results, t)
+	}
+	return results, nil
+}
+
+type ddlTokenKind int
+
+const ddlIdent = ddlTokenKind(0)
+const ddlQuotedIdent = ddlTokenKind(1)
+const ddlString = ddlTokenKind(2)
+const ddlNumber = ddlTokenKind(3)
+const ddlPunct = ddlTokenKind(4)
+
+type ddlToken struct {
+	kind ddlTokenKind
+	text string
+	line int
+}
+
+// is checks for an unquoted keyword or a punctuation character
+func (this ddlToken) is(v string) bool {
+	return (this.kind == ddlIdent || this.kind == ddlPunct) && this.text == v
+}
+
+type DDLSyntaxError struct {
+	Line    int
+	Message string
+}
+
+func (this DDLSyntaxError) Error() string {
+	return fmt.Sprintf("line %d:%s", this.Line, this.Message)
+}
+
+// ddlEscape returns the character written as a backslash followed by c
+// in an escape string
+func ddlEscape(c rune) rune {
+	switch c {
+	case 'b':
+		return '\b'
+	case 'f':
+		return '\f'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	}
+	return c
+}
+
+func tokenizeDDL(src string) ([]ddlToken, error) {
+	var tokens []ddlToken
+	runes := []rune(src)
+	line := 1
+	startOfLine := true
+
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == '\n':
+			line++
+			startOfLine = true
+			i++
+			continue
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case c == '\\' && startOfLine:
+			//psql meta-command such as \set, skip the line
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/') {
+				if runes[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, DDLSyntaxError{Line: line, Message: "unterminated comment"}
+			}
+			i += 2
+			continue
+		}
+		startOfLine = false
+
+		switch {
+		case c == '"' || c == '\'':
+			//Quoted identifier or string literal, a doubled
+			//quote character is an escaped quote
+			start := line
+			var text []rune
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, DDLSyntaxError{Line: start, Message: "unterminated quote"}
+				}
+				if runes[i] == c {
+					if i+1 < len(runes) && runes[i+1] == c {
+						text = append(text, c)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				if runes[i] == '\n' {
+					line++
+				}
+				text = append(text, runes[i])
+				i++
+			}
+			kind := ddlString
+			if c == '"' {
+				kind = ddlQuotedIdent
+			}
+			tokens = append(tokens, ddlToken{kind: kind, text: string(text), line: start})
+		case (c == 'e' || c == 'E') && i+1 < len(runes) && runes[i+1] == '\'':
+			//Escape string such as E'it\'s', a backslash escapes
+			//the next character
+			start := line
+			var text []rune
+			i += 2
+			for {
+				if i >= len(runes) {
+					return nil, DDLSyntaxError{Line: start, Message: "unterminated quote"}
+				}
+				r := runes[i]
+				if r == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						text = append(text, r)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				if r == '\\' && i+1 < len(runes) {
+					i++
+					r = ddlEscape(runes[i])
+				}
+				if runes[i] == '\n' {
+					line++
+				}
+				text = append(text, r)
+				i++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlString, text: string(text), line: start})
+		case c == '$' && dollarQuoteTag(runes[i:]) != "":
+			//Dollar quoted string such as a function body
+			start := line
+			tag := []rune(dollarQuoteTag(runes[i:]))
+			i += len(tag)
+			j := i
+			for ; j+len(tag) <= len(runes); j++ {
+				if string(runes[j:j+len(tag)]) == string(tag) {
+					break
+				}
+				if runes[j] == '\n' {
+					line++
+				}
+			}
+			if j+len(tag) > len(runes) {
+				return nil, DDLSyntaxError{Line: start, Message: "unterminated dollar quote"}
+			}
+			tokens = append(tokens, ddlToken{kind: ddlString, text: string(runes[i:j]), line: start})
+			i = j + len(tag)
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlIdent, text: strings.ToLower(string(runes[i:j])), line: line})
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlNumber, text: string(runes[i:j]), line: line})
+			i = j
+		default:
+			tokens = append(tokens, ddlToken{kind: ddlPunct, text: string(c), line: line})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+// dollarQuoteTag returns the opening tag of a dollar quoted string
+// such as "$$" or "$body$" at the start of v, or an empty string
+func dollarQuoteTag(v []rune) string {
+	for i := 1; i < len(v); i++ {
+		if v[i] == '$' {
+			return string(v[0 : i+1])
+		}
+		if !unicode.IsLetter(v[i]) && v[i] != '_' && !(i > 1 && unicode.IsDigit(v[i])) {
+			return ""
+		}
+	}
+	return ""
+}
+
+type ddlParser struct {
+	tokens  []ddlToken
+	pos     int
+	schema  string
+	tables  []*SnapshotTable
+	byName  map[string]*SnapshotTable
+	enums   map[string][]string
+	domains map[string]ddlType
+}
+
+// ddlType is a type name and its type modifiers, such as the
+// precision and scale of numeric(12,2)
+type ddlType struct {
+	name      string
+	modifiers []int
+}
+
+// ParseDDL parses the DDL statements in src and returns the tables
+// that belong to schema in the order they are declared. Names that
+// are not schema qualified are assumed to belong to schema.
+func ParseDDL(src string, schema string) ([]*SnapshotTable, error) {
+	tokens, err := tokenizeDDL(src)
+	if err != nil {
+		return nil, err
+	}
+
+	this := &ddlParser{
+		tokens:  tokens,
+		schema:  schema,
+		byName:  make(map[string]*SnapshotTable),
+		enums:   make(map[string][]string),
+		domains: make(map[string]ddlType),
+	}
+
+	for !this.done() {
+		err = this.statement()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return this.tables, nil
+}
+
+func (this *ddlParser) done() bool {
+	return this.pos >= len(this.tokens)
+}
+
+func (this *ddlParser) peek() ddlToken {
+	if this.done() {
+		return ddlToken{kind: ddlPunct, text: ";", line: this.lastLine()}
+	}
+	return this.tokens[this.pos]
+}
+
+func (this *ddlParser) lastLine() int {
+	if len(this.tokens) == 0 {
+		return 1
+	}
+	return this.tokens[len(this.tokens)-1].line
+}
+
+func (this *ddlParser) next() ddlToken {
+	t := this.peek()
+	if !this.done() {
+		this.pos++
+	}
+	return t
+}
+
+// accept consumes the keywords if they are next
+func (this *ddlParser) accept(keywords ...string) bool {
+	for i, k := range keywords {
+		if this.pos+i >= len(this.tokens) || !this.tokens[this.pos+i].is(k) {
+			return false
+		}
+	}
+	this.pos += len(keywords)
+	return true
+}
+
+func (this *ddlParser) expect(keywords ...string) error {
+	if !this.accept(keywords...) {
+		return this.errorf("expected %q near %q", strings.Join(keywords, " "), this.peek().text)
+	}
+	return nil
+}
+
+func (this *ddlParser) errorf(fmtStr string, args ...interface{}) error {
+	return DDLSyntaxError{Line: this.peek().line, Message: fmt.Sprintf(fmtStr, args...)}
+}
+
+func (this *ddlParser) identifier() (string, error) {
+	t := this.next()
+	if t.kind != ddlIdent && t.kind != ddlQuotedIdent {
+		return "", DDLSyntaxError{Line: t.line, Message: fmt.Sprintf("expected identifier near %q", t.text)}
+	}
+	return t.text, nil
+}
+
+// qualifiedName returns the name and a boolean indicating if
+// it belongs to the schema being parsed
+func (this *ddlParser) qualifiedName() (string, bool, error) {
+	name, err := this.identifier()
+	if err != nil {
+		return "", false, err
+	}
+	if this.accept(".") {
+		schema := name
+		name, err = this.identifier()
+		if err != nil {
+			return "", false, err
+		}
+		return name, schema == this.schema, nil
+	}
+	return name, true, nil
+}
+
+// skip consumes tokens until one of the stop tokens is found
+// outside of any parentheses. The stop token is not consumed.
+func (this *ddlParser) skip(stop ...string) {
+	depth := 0
+	for !this.done() {
+		t := this.peek()
+		if depth == 0 {
+			for _, s := range stop {
+				if t.is(s) {
+					return
+				}
+			}
+		}
+		if t.is("(") || t.is("[") {
+			depth++
+		} else if t.is(")") || t.is("]") {
+			depth--
+			if depth < 0 {
+				return
+			}
+		} else if t.is(";") {
+			return
+		}
+		this.pos++
+	}
+}
+
+func (this *ddlParser) skipStatement() {
+	this.skip(";")
+	this.accept(";")
+}
+
+func (this *ddlParser) columnList() ([]string, error) {
+	var result []string
+	err := this.expect("(")
+	if err != nil {
+		return nil, err
+	}
+	for {
+		name, err := this.identifier()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, name)
+		if this.accept(")") {
+			return result, nil
+		}
+		err = this.expect(",")
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (this *ddlParser) statement() error {
+	switch {
+	case this.accept("create"):
+		this.accept("or", "replace")
+		for this.accept("global") || this.accept("local") || this.accept("temporary") ||
+			this.accept("temp") || this.accept("unlogged") {
+		}
+		switch {
+		case this.accept("table"):
+			return this.createTable()
+		case this.accept("type"):
+			return this.createType()
+		case this.accept("domain"):
+			return this.createDomain()
+		}
+	case this.accept("alter", "table"):
+		return this.alterTable()
+	}
+	this.skipStatement()
+	return nil
+}
+
+func (this *ddlParser) createTable() error {
+	this.accept("if", "not", "exists")
+	name, ours, err := this.qualifiedName()
+	if err != nil {
+		return err
+	}
+
+	t := &SnapshotTable{TableName: name}
+	if !this.peek().is("(") {
+		//CREATE TABLE ... AS, CREATE TABLE ... PARTITION OF, etc.
+		this.skipStatement()
+		return nil
+	}
+	this.next()
+
+	for !this.accept(")") {
+		if this.done() {
+			return this.errorf("unterminated table definition of %q", name)
+		}
+		if this.peek().is(",") {
+			this.next()
+			continue
+		}
+
+		switch {
+		case this.peek().is("constraint"), this.peek().is("primary"), this.peek().is("unique"),
+			this.peek().is("foreign"), this.peek().is("check"), this.peek().is("exclude"):
+			err = this.tableConstraint(t)
+		case this.peek().is("like"):
+			this.skip(",", ")")
+		default:
+			err = this.columnDefinition(t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	this.skipStatement()
+
+	if ours {
+		if _, ok := this.byName[name]; ok {
+			return this.errorf("table %q declared twice", name)
+		}
+		this.byName[name] = t
+		this.tables = append(this.tables, t)
+	}
+	return nil
+}
+
+func (this *ddlParser) columnDefinition(t *SnapshotTable) error {
+	line := this.peek().line
+	name, err := this.identifier()
+	if err != nil {
+		return err
+	}
+
+	typ, err := this.typeName()
+	if err != nil {
+		return err
+	}
+
+	col := &SnapshotColumn{ColumnName: name, IsNullable: true}
+	if base, ok := this.domains[typ.name]; ok {
+		typ = base
+	}
+	typname := typ.name
+	labels, isEnum := this.enums[typname]
+
+	switch typname {
+	case "serial", "serial4":
+		typname = "int4"
+		col.IsNullable = false
+	case "bigserial", "serial8":
+		typname = "int8"
+		col.IsNullable = false
+	case "smallserial", "serial2":
+		typname = "int2"
+		col.IsNullable = false
+	}
+
+	switch {
+	case isEnum:
+		col.SqlType = SqlText
+		col.Labels = labels
+	default:
+		col.SqlType, err = pgTypeToSqlDataType(typname, t.TableName, name)
+		if err == ErrSkipColumn {
+			//Parse the rest of the definition but drop the column
+			col = nil
+		} else if err != nil {
+			return DDLSyntaxError{Line: line, Message: err.Error()}
+		}
+		if col != nil && col.SqlType == SqlNumeric && len(typ.modifiers) != 0 {
+			col.Precision = typ.modifiers[0]
+			if len(typ.modifiers) > 1 {
+				col.Scale = typ.modifiers[1]
+			}
+		}
+	}
+
+	for {
+		t0 := this.peek()
+		switch {
+		case t0.is(","), t0.is(")"), t0.is(";"):
+			if col != nil {
+				t.TableColumns = append(t.TableColumns, col)
+			}
+			return nil
+		case this.accept("not", "null"):
+			if col != nil {
+				col.IsNullable = false
+			}
+		case this.accept("null"):
+		case this.accept("identity"):
+			//GENERATED ... AS IDENTITY is implicitly NOT NULL
+			if col != nil {
+				col.IsNullable = false
+			}
+		case this.accept("constraint"):
+			_, err = this.identifier()
+			if err != nil {
+				return err
+			}
+		case this.accept("primary", "key"):
+			t.PrimaryKeyColumns = append(t.PrimaryKeyColumns, name)
+			if col != nil {
+				col.IsNullable = false
+			}
+		case this.accept("unique"):
+			t.UniqueColumns = append(t.UniqueColumns, name)
+		case this.accept("references"):
+			t.ForeignKeyColumns = append(t.ForeignKeyColumns, name)
+		case t0.is("("):
+			//Expressions of DEFAULT, CHECK, GENERATED, etc.
+			this.next()
+			this.skip(")")
+			this.accept(")")
+		default:
+			this.next()
+		}
+	}
+}
+
+// typeName parses a type and returns the internal Postgres name of
+// it, such as "int4" for "integer", and its numeric type modifiers.
+// Array types are returned with a leading underscore.
+func (this *ddlParser) typeName() (ddlType, error) {
+	var result ddlType
+	name, _, err := this.qualifiedName()
+	if err != nil {
+		return result, err
+	}
+
+	switch name {
+	case "double":
+		err = this.expect("precision")
+		name = "float8"
+	case "character", "char":
+		if this.accept("varying") {
+			name = "varchar"
+		} else {
+			name = "bpchar"
+		}
+	case "bit":
+		if this.accept("varying") {
+			name = "varbit"
+		}
+	case "timestamp", "time":
+		if this.peek().is("(") {
+			this.next()
+			this.skip(")")
+			this.accept(")")
+		}
+		if this.accept("with", "time", "zone") {
+			name = name + "tz"
+		} else {
+			this.accept("without", "time", "zone")
+		}
+	case "int", "integer":
+		name = "int4"
+	case "bigint":
+		name = "int8"
+	case "smallint":
+		name = "int2"
+	case "real", "float":
+		//FLOAT without a precision is a double precision
+		if name == "float" {
+			name = "float8"
+		} else {
+			name = "float4"
+		}
+	case "boolean":
+		name = "bool"
+	case "decimal":
+		name = "numeric"
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if this.peek().is("(") {
+		//Type modifiers such as the length of a VARCHAR
+		this.next()
+		for !this.done() && !this.peek().is(")") {
+			t := this.next()
+			if t.kind == ddlNumber {
+				v, err := strconv.Atoi(t.text)
+				if err != nil {
+					return result, DDLSyntaxError{Line: t.line, Message: err.Error()}
+				}
+				result.modifiers = append(result.modifiers, v)
+			}
+		}
+		err = this.expect(")")
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for this.accept("[") {
+		this.skip("]")
+		err = this.expect("]")
+		if err != nil {
+			return result, err
+		}
+		if !strings.HasPrefix(name, "_") {
+			name = "_" + name
+		}
+	}
+	if this.accept("array") {
+		if !strings.HasPrefix(name, "_") {
+			name = "_" + name
+		}
+	}
+
+	result.name = name
+	return result, nil
+}
+
+func (this *ddlParser) tableConstraint(t *SnapshotTable) error {
+	if this.accept("constraint") {
+		_, err := this.identifier()
+		if err != nil {
+			return err
+		}
+	}
+
+	var err error
+	var columns []string
+	switch {
+	case this.accept("primary", "key"):
+		columns, err = this.columnList()
+		if err != nil {
+			return err
+		}
+		t.PrimaryKeyColumns = append(t.PrimaryKeyColumns, columns...)
+		for _, c := range t.TableColumns {
+			for _, pk := range columns {
+				if c.ColumnName == pk {
+					c.IsNullable = false
+				}
+			}
+		}
+	case this.accept("unique"):
+		this.accept("nulls", "distinct")
+		this.accept("nulls", "not", "distinct")
+		columns, err = this.columnList()
+		if err != nil {
+			return err
+		}
+		//Only a single column UNIQUE constraint identifies a row,
+		//the same as the other adapters
+		if len(columns) == 1 {
+			t.UniqueColumns = append(t.UniqueColumns, columns...)
+		}
+	case this.accept("foreign", "key"):
+		columns, err = this.columnList()
+		if err != nil {
+			return err
+		}
+		t.ForeignKeyColumns = append(t.ForeignKeyColumns, columns...)
+	}
+
+	this.skip(",", ")")
+	return nil
+}
+
+func (this *ddlParser) createType() error {
+	name, ours, err := this.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !this.accept("as", "enum") {
+		this.skipStatement()
+		return nil
+	}
+
+	err = this.expect("(")
+	if err != nil {
+		return err
+	}
+	labels := []string{}
+	for !this.accept(")") {
+		t := this.next()
+		switch {
+		case t.kind == ddlString:
+			labels = append(labels, t.text)
+		case t.is(","):
+		default:
+			return DDLSyntaxError{Line: t.line, Message: fmt.Sprintf("expected enum label near %q", t.text)}
+		}
+	}
+	this.skipStatement()
+
+	if ours {
+		this.enums[name] = labels
+	}
+	return nil
+}
+
+func (this *ddlParser) createDomain() error {
+	name, ours, err := this.qualifiedName()
+	if err != nil {
+		return err
+	}
+	this.accept("as")
+	base, err := this.typeName()
+	if err != nil {
+		return err
+	}
+	this.skipStatement()
+
+	if ours {
+		this.domains[name] = base
+	}
+	return nil
+}
+
+func (this *ddlParser) alterTable() error {
+	this.accept("if", "exists")
+	this.accept("only")
+	name, ours, err := this.qualifiedName()
+	if err != nil {
+		return err
+	}
+	this.accept("*")
+
+	t, ok := this.byName[name]
+	if !ok || !ours {
+		this.skipStatement()
+		return nil
+	}
+
+	for {
+		if this.accept("add") {
+			switch {
+			case this.peek().is("constraint"), this.peek().is("primary"), this.peek().is("unique"),
+				this.peek().is("foreign"), this.peek().is("check"), this.peek().is("exclude"):
+				err = this.tableConstraint(t)
+			default:
+				this.accept("column")
+				this.accept("if", "not", "exists")
+				err = this.columnDefinition(t)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		this.skip(",", ";")
+		if !this.accept(",") {
+			break
+		}
+	}
+	this.skipStatement()
+	return nil
+}
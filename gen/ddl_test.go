@@ -0,0 +1,195 @@
+package sillyquill_gen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDDLGenTestSchema(t *testing.T) {
+	src, err := os.ReadFile("../gen_test/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables, err := ParseDDL(string(src), "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, v := range tables {
+		names = append(names, v.Name())
+	}
+	expected := []string{"trucks", "cars", "incidents", "pizza_delivery_guys",
+		"wheels", "archive_files", "numbers", "null_numbers", "prices",
+		"not_uniquely_identifiables", "invoices", "audit_log"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("got tables %v; want %v", names, expected)
+	}
+
+	trucks := tables[0]
+	expectedColumns := []SnapshotColumn{
+		{ColumnName: "id", SqlType: SqlInt},
+		{ColumnName: "created_at", SqlType: SqlTimestamp},
+		{ColumnName: "updated_at", SqlType: SqlTimestamp},
+		{ColumnName: "make", SqlType: SqlVarChar},
+		{ColumnName: "model", SqlType: SqlVarChar},
+		{ColumnName: "tonnage", SqlType: SqlReal},
+	}
+	if len(trucks.TableColumns) != len(expectedColumns) {
+		t.Fatalf("got %d columns; want %d", len(trucks.TableColumns), len(expectedColumns))
+	}
+	for i, v := range trucks.TableColumns {
+		if !reflect.DeepEqual(*v, expectedColumns[i]) {
+			t.Errorf("got column %+v; want %+v", *v, expectedColumns[i])
+		}
+	}
+	if !reflect.DeepEqual(trucks.PrimaryKeyColumns, []string{"make", "model"}) {
+		t.Errorf("got primary key %v", trucks.PrimaryKeyColumns)
+	}
+	if !reflect.DeepEqual(trucks.UniqueColumns, []string{"id"}) {
+		t.Errorf("got unique %v", trucks.UniqueColumns)
+	}
+
+	incidents := tables[2]
+	if incidents.TableColumns[0].Nullable() || incidents.TableColumns[0].DataType() != SqlBigInt {
+		t.Errorf("got %+v for incidents.id", *incidents.TableColumns[0])
+	}
+
+	wheels := tables[4]
+	if !reflect.DeepEqual(wheels.ForeignKeyColumns, []string{"car_id"}) {
+		t.Errorf("got foreign keys %v", wheels.ForeignKeyColumns)
+	}
+
+	pizza := tables[3]
+	if pizza.TableColumns[0].Nullable() {
+		t.Errorf("primary key column %q is nullable", pizza.TableColumns[0].Name())
+	}
+}
+
+func TestParseDDLStatements(t *testing.T) {
+	const src = `
+-- comment with a ; in it
+CREATE TYPE mood AS ENUM ('sad', 'ok', 'it''s great', E'it\'s\tfine');
+CREATE DOMAIN money_amount AS numeric(12,2) CHECK (VALUE >= 0);
+CREATE FUNCTION f() RETURNS int AS $body$ select 1; $body$ LANGUAGE sql;
+/* block
+   comment */
+CREATE TABLE public."People" (
+	id integer GENERATED ALWAYS AS IDENTITY,
+	feeling mood DEFAULT 'ok'::mood NOT NULL,
+	balance money_amount,
+	tags text[],
+	search tsvector,
+	seen_at timestamp(3) without time zone,
+	CONSTRAINT people_feeling_check CHECK (feeling <> 'sad'),
+	CONSTRAINT people_feeling_seen_key UNIQUE (feeling, seen_at)
+) PARTITION BY RANGE (id);
+CREATE TABLE other.ignored (id int);
+CREATE UNIQUE INDEX people_id ON "People" (id);
+ALTER TABLE ONLY public."People"
+	ADD CONSTRAINT people_pkey PRIMARY KEY (id),
+	ADD COLUMN nickname character varying(32) UNIQUE;
+`
+	tables, err := ParseDDL(src, "public")
+	if err == nil {
+		t.Fatalf("expected error for array column; got %v", tables)
+	}
+	if v, ok := err.(DDLSyntaxError); !ok || v.Line != 12 {
+		t.Fatalf("got %v; want error on line 12", err)
+	}
+
+	tables, err = ParseDDL(strings.Replace(src, "\ttags text[],\n", "", 1), "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || tables[0].Name() != "People" {
+		t.Fatalf("got %v", tables)
+	}
+	people := tables[0]
+	expectedColumns := []SnapshotColumn{
+		{ColumnName: "id", SqlType: SqlInt},
+		{ColumnName: "feeling", SqlType: SqlText, Labels: []string{"sad", "ok", "it's great", "it's\tfine"}},
+		{ColumnName: "balance", SqlType: SqlNumeric, IsNullable: true, Precision: 12, Scale: 2},
+		{ColumnName: "seen_at", SqlType: SqlTimestamp, IsNullable: true},
+		{ColumnName: "nickname", SqlType: SqlVarChar, IsNullable: true},
+	}
+	if len(people.TableColumns) != len(expectedColumns) {
+		t.Fatalf("got %d columns; want %d", len(people.TableColumns), len(expectedColumns))
+	}
+	for i, v := range people.TableColumns {
+		if !reflect.DeepEqual(*v, expectedColumns[i]) {
+			t.Errorf("got column %+v; want %+v", *v, expectedColumns[i])
+		}
+	}
+	if !reflect.DeepEqual(people.PrimaryKeyColumns, []string{"id"}) {
+		t.Errorf("got primary key %v", people.PrimaryKeyColumns)
+	}
+	if !reflect.DeepEqual(people.UniqueColumns, []string{"nickname"}) {
+		t.Errorf("got unique %v", people.UniqueColumns)
+	}
+}
+
+func TestSchemaAdaptersAgree(t *testing.T) {
+	const src = `
+CREATE TABLE parts (
+	id serial PRIMARY KEY,
+	sku text UNIQUE,
+	make text,
+	model text,
+	UNIQUE (make, model)
+);
+CREATE TABLE orders (
+	id bigint,
+	part_id integer REFERENCES parts (id),
+	CONSTRAINT orders_pkey PRIMARY KEY (id)
+);
+`
+	parsed, err := ParseDDL(src, "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []Table
+	for _, v := range parsed {
+		tables = append(tables, v)
+	}
+	snapshot, err := NewSnapshot("public", tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := snapshot.WriteFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	adapter := &SnapshotAdapter{File: filename}
+	read, err := adapter.Tables()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][3][]string{
+		"parts":  {{"id"}, {"sku"}, nil},
+		"orders": {{"id"}, nil, {"part_id"}},
+	}
+	for _, list := range [][]Table{tables, read} {
+		if len(list) != len(expected) {
+			t.Fatalf("got %d tables; want %d", len(list), len(expected))
+		}
+		for _, table := range list {
+			want := expected[table.Name()]
+			pk, _ := table.PrimaryKey()
+			unique, _ := table.Unique()
+			fks, _ := table.ForeignKeys()
+			for i, got := range [][]string{pk, unique, fks} {
+				if len(got) != 0 || len(want[i]) != 0 {
+					if !reflect.DeepEqual(got, want[i]) {
+						t.Errorf("%s constraint #%d got %v; want %v", table.Name(), i, got, want[i])
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package sillyquill_gen
+
+import "bytes"
+import "fmt"
+import "strings"
+
+const diffContext = 3
+
+type diffOp struct {
+	kind byte
+	text string
+}
+
+func splitLines(v []byte) []string {
+	if len(v) == 0 {
+		return nil
+	}
+	lines := strings.Split(string(v), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the edit script turning a into b computed from
+// the longest common subsequence of the lines
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// unifiedDiff returns the difference between a and b in the unified
+// format, or an empty string if they are the same
+func unifiedDiff(aName, bName string, a, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n", aName)
+	fmt.Fprintf(&buf, "+++ %s\n", bName)
+
+	//aLine and bLine are the line numbers of ops[i]
+	aLine, bLine := 0, 0
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			aLine++
+			bLine++
+			i++
+			continue
+		}
+
+		//Extend the hunk across runs of unchanged lines
+		//too short to separate two hunks
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContext {
+				break
+			}
+			end = run
+		}
+
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		stop := end + diffContext
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		aStart, bStart := aLine-(i-start), bLine-(i-start)
+		var aLength, bLength int
+		for _, op := range ops[start:stop] {
+			if op.kind != '+' {
+				aLength++
+			}
+			if op.kind != '-' {
+				bLength++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(aStart, aLength), hunkRange(bStart, bLength))
+		for _, op := range ops[start:stop] {
+			fmt.Fprintf(&buf, "%c%s\n", op.kind, op.text)
+		}
+
+		aLine += aLength - (i - start)
+		bLine += bLength - (i - start)
+		i = stop
+	}
+
+	return buf.String()
+}
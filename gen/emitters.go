@@ -0,0 +1,193 @@
+package sillyquill_gen
+
+import "bytes"
+import "encoding/json"
+import "fmt"
+import "io"
+import "os/exec"
+import "sort"
+import "strings"
+import "sync"
+
+// EmitterFactory creates the CodeEmitter of an optional emitter for
+// a table. The CodeEmitter produces the file <table><suffix>.go
+type EmitterFactory func(table Table, model *ColumnizedStruct, templates *Templates) (CodeEmitter, error)
+
+// EmitterRegistry maps names used in the configuration to emitters
+// that are only run when enabled
+type EmitterRegistry struct {
+	lock      sync.RWMutex
+	factories map[string]EmitterFactory
+}
+
+func NewEmitterRegistry() *EmitterRegistry {
+	return &EmitterRegistry{
+		factories: make(map[string]EmitterFactory),
+	}
+}
+
+// DefaultEmitters is the registry used when none is specified
+var DefaultEmitters = NewEmitterRegistry()
+
+func init() {
+	RegisterEmitter(JSONTemplateName, NewTemplateEmitterFactory(JSONTemplateName))
+}
+
+// RegisterEmitter adds a named emitter to DefaultEmitters. It panics
+// if the name is already registered.
+func RegisterEmitter(name string, factory EmitterFactory) {
+	err := DefaultEmitters.Register(name, factory)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (this *EmitterRegistry) Register(name string, factory EmitterFactory) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if _, ok := this.factories[name]; ok {
+		return fmt.Errorf("Emitter %q is already registered", name)
+	}
+	this.factories[name] = factory
+	return nil
+}
+
+func (this *EmitterRegistry) Lookup(name string) (EmitterFactory, bool) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	factory, ok := this.factories[name]
+	return factory, ok
+}
+
+// Names returns the registered names in sorted order
+func (this *EmitterRegistry) Names() []string {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	var result []string
+	for name, _ := range this.factories {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// NewTemplateEmitterFactory returns an EmitterFactory that renders the
+// named template, producing the file <table>_<name>.go
+func NewTemplateEmitterFactory(name string) EmitterFactory {
+	return func(table Table, model *ColumnizedStruct, templates *Templates) (CodeEmitter, error) {
+		t := templates.Lookup(name)
+		if t == nil {
+			return nil, fmt.Errorf("No template named %q", name)
+		}
+		return &TemplateEmitter{
+			Template: t,
+			Data:     model,
+		}, nil
+	}
+}
+
+type UnknownEmitterError struct {
+	Name string
+}
+
+func (this UnknownEmitterError) Error() string {
+	return fmt.Sprintf("Unknown emitter %q", this.Name)
+}
+
+// PluginField describes the struct field generated for a column
+type PluginField struct {
+	Column   string `json:"column"`
+	Name     string `json:"name"`
+	DataType string `json:"type"`
+}
+
+// PluginModel describes the names used by the generated code
+type PluginModel struct {
+	SingularName string        `json:"singular_name"`
+	PluralName   string        `json:"plural_name"`
+	ListTypeName string        `json:"list_type_name"`
+	Fields       []PluginField `json:"fields"`
+}
+
+// PluginInput is the JSON written to the standard input of a plugin
+type PluginInput struct {
+	Package string         `json:"package"`
+	Table   *SnapshotTable `json:"table"`
+	Model   PluginModel    `json:"model"`
+}
+
+// PluginEmitter runs an external command for each table. The command
+// receives a PluginInput as JSON on standard input and writes Go
+// source to standard output. The package clause is written for it,
+// so the output starts with any imports it needs followed by the
+// declarations.
+type PluginEmitter struct {
+	Name    string
+	Command []string
+	Input   PluginInput
+}
+
+// NewPluginEmitterFactory returns an EmitterFactory that runs command
+// for each table, producing the file <table>_<name>.go
+func NewPluginEmitterFactory(name string, command []string, pkg string) EmitterFactory {
+	return func(table Table, model *ColumnizedStruct, templates *Templates) (CodeEmitter, error) {
+		if len(command) == 0 {
+			return nil, fmt.Errorf("Plugin %q has no command", name)
+		}
+		st, err := NewSnapshotTable(table)
+		if err != nil {
+			return nil, err
+		}
+		this := &PluginEmitter{
+			Name:    name,
+			Command: command,
+			Input: PluginInput{
+				Package: pkg,
+				Table:   st,
+				Model: PluginModel{
+					SingularName: model.SingularModelName,
+					PluralName:   model.PluralModelName,
+					ListTypeName: model.ListTypeName,
+				},
+			},
+		}
+		for i, field := range model.Fields {
+			this.Input.Model.Fields = append(this.Input.Model.Fields, PluginField{
+				Column:   model.Columns[i].Name(),
+				Name:     field.Name,
+				DataType: field.DataType,
+			})
+		}
+		return this, nil
+	}
+}
+
+func (this *PluginEmitter) Imports() []string {
+	return nil
+}
+
+func (this *PluginEmitter) Suffix() string {
+	return "_" + this.Name
+}
+
+func (this *PluginEmitter) Emit(w io.Writer) error {
+	input, err := json.Marshal(this.Input)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(this.Command[0], this.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("Plugin %q failed for table %q:%v:%s",
+			this.Name,
+			this.Input.Table.TableName,
+			err,
+			strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package sillyquill_gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+var carsTable = &SnapshotTable{
+	TableName:         "cars",
+	PrimaryKeyColumns: []string{"id"},
+	TableColumns: []*SnapshotColumn{
+		{ColumnName: "id", SqlType: SqlInt},
+		{ColumnName: "make", SqlType: SqlText},
+	},
+}
+
+type tableNameEmitter struct {
+	model *ColumnizedStruct
+}
+
+func (this tableNameEmitter) Imports() []string {
+	return nil
+}
+
+func (this tableNameEmitter) Suffix() string {
+	return "_table_name"
+}
+
+func (this tableNameEmitter) Emit(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "func (this *%s) TableName() string {\nreturn %q\n}\n",
+		this.model.SingularModelName,
+		this.model.TableName)
+	return err
+}
+
+func TestEmitterRegistry(t *testing.T) {
+	registry := NewEmitterRegistry()
+	factory := func(table Table, model *ColumnizedStruct, templates *Templates) (CodeEmitter, error) {
+		return tableNameEmitter{model}, nil
+	}
+	err := registry.Register("table_name", factory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = registry.Register("table_name", factory)
+	if err == nil {
+		t.Errorf("registered the same name twice")
+	}
+
+	me := NewModelEmitter()
+	me.Package = "dal"
+	me.Registry = registry
+	me.Emitters = []string{"table_name"}
+	files, err := me.Render(carsTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 5 {
+		t.Errorf("got %d files; want 5", len(files))
+	}
+	if !strings.Contains(string(files["cars_table_name.go"]), `return "cars"`) {
+		t.Errorf("got\n%s", files["cars_table_name.go"])
+	}
+
+	me.Emitters = []string{"mock"}
+	_, err = me.Render(carsTable)
+	if _, ok := err.(UnknownEmitterError); !ok {
+		t.Errorf("got %v; want UnknownEmitterError", err)
+	}
+}
+
+// TestPluginHelperProcess is not a real test, it is run as a plugin
+// by TestPluginEmitter
+func TestPluginHelperProcess(t *testing.T) {
+	if os.Getenv("SILLYQUILL_TEST_PLUGIN") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	var input PluginInput
+	err := json.NewDecoder(os.Stdin).Decode(&input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if input.Table.TableName == "broken" {
+		fmt.Fprintln(os.Stderr, "cannot handle table")
+		os.Exit(1)
+	}
+	fmt.Printf("import \"strings\"\n\nfunc (this *%s) Columns() string {\nreturn strings.Join([]string{", input.Model.SingularName)
+	for _, field := range input.Model.Fields {
+		fmt.Printf("%q,", field.Column+":"+field.DataType)
+	}
+	fmt.Printf("}, %q)\n}\n", " ")
+}
+
+func TestPluginEmitter(t *testing.T) {
+	os.Setenv("SILLYQUILL_TEST_PLUGIN", "1")
+	defer os.Unsetenv("SILLYQUILL_TEST_PLUGIN")
+
+	registry := NewEmitterRegistry()
+	command := []string{os.Args[0], "-test.run=TestPluginHelperProcess"}
+	err := registry.Register("plugin", NewPluginEmitterFactory("plugin", command, "dal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	me := NewModelEmitter()
+	me.Package = "dal"
+	me.Registry = registry
+	me.Emitters = []string{"plugin"}
+	files, err := me.Render(carsTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(files["cars_plugin.go"])
+	if !strings.Contains(src, "package dal") ||
+		!strings.Contains(src, `"id:int32", "make:string"`) {
+		t.Errorf("got\n%s", src)
+	}
+
+	_, err = me.Render(&SnapshotTable{
+		TableName:    "broken",
+		TableColumns: carsTable.TableColumns,
+	})
+	if err == nil || !strings.Contains(err.Error(), "cannot handle table") {
+		t.Errorf("got %v; want error from plugin", err)
+	}
+}
+
+func TestJSONEmitter(t *testing.T) {
+	me := NewModelEmitter()
+	me.Package = "dal"
+	me.Emitters = []string{"json"}
+	me.StructTags = StructTags{
+		Styles: map[string]string{"json": "snake,omitempty"},
+		Overrides: map[string]map[string]map[string]string{
+			"cars": {"make": {"json": "-"}},
+		},
+	}
+	files, err := me.Render(carsTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(files["cars_json.go"])
+	for _, expected := range []string{
+		"func (this Car) MarshalJSON() ([]byte, error) {",
+		`if this.IsLoaded.Id || this.IsSet.Id {`,
+		`sillyquill_rt.JSONField{Name: "id", Value: this.Id}`,
+		"func (this *Car) UnmarshalJSON(data []byte) error {",
+		`if raw, ok := members["id"]; ok {`,
+		"this.SetId(v)",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("%s not in\n%s", expected, src)
+		}
+	}
+	if strings.Contains(src, "Make") || strings.Contains(src, "Value: nil") {
+		t.Errorf("got\n%s", src)
+	}
+
+	me.JSONNulls = true
+	files, err = me.Render(carsTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(files["cars_json.go"]), `sillyquill_rt.JSONField{Name: "id", Value: nil}`) {
+		t.Errorf("got\n%s", files["cars_json.go"])
+	}
+
+	prices := &SnapshotTable{
+		TableName: "prices",
+		TableColumns: []*SnapshotColumn{
+			{ColumnName: "id", SqlType: SqlInt},
+			{ColumnName: "amount", SqlType: SqlNumeric},
+		},
+	}
+	me.JSONNumbers = true
+	files, err = me.Render(prices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src = string(files["prices_json.go"])
+	if !strings.Contains(src, `sillyquill_rt.JSONField{Name: "amount", Value: sillyquill_rt.JSONNumber(this.Amount)}`) ||
+		!strings.Contains(src, `sillyquill_rt.JSONField{Name: "id", Value: this.Id}`) {
+		t.Errorf("got\n%s", src)
+	}
+}
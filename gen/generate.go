@@ -0,0 +1,271 @@
+package sillyquill_gen
+
+import "context"
+import "fmt"
+import "os"
+import "path/filepath"
+import "sort"
+import "sync"
+
+import "github.com/spiceworks/spicelog"
+
+type TableError struct {
+	TableName string
+	Err       error
+}
+
+func (this TableError) Error() string {
+	return fmt.Sprintf("Error processing table %q:%v", this.TableName, this.Err)
+}
+
+// processTables calls f for each table, with at most concurrency calls
+// running at once. Unless keepGoing is true no further tables are
+// started once a call fails. No further tables are started once ctx
+// is done. The errors are returned in the order of tables.
+func processTables(ctx context.Context, tables []Table, concurrency int, keepGoing bool, f func(Table) error) []TableError {
+	errs := make([]error, len(tables))
+	var failed bool
+	var lock sync.Mutex
+
+	sem := make(chan struct{}, concurrency)
+	wg := new(sync.WaitGroup)
+	for i, table := range tables {
+		sem <- struct{}{}
+
+		lock.Lock()
+		stop := (failed && !keepGoing) || ctx.Err() != nil
+		lock.Unlock()
+		if stop {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, t Table) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := f(t)
+			if err != nil {
+				lock.Lock()
+				failed = true
+				lock.Unlock()
+			}
+			errs[i] = err
+		}(i, table)
+	}
+	wg.Wait()
+
+	var result []TableError
+	for i, err := range errs {
+		if err != nil {
+			result = append(result, TableError{
+				TableName: tables[i].Name(),
+				Err:       err,
+			})
+		}
+	}
+	return result
+}
+
+// Sink receives the generated files
+type Sink interface {
+	WriteFile(filename string, data []byte) error
+}
+
+// DirSink writes the generated files to a directory
+type DirSink struct {
+	Dir string
+}
+
+func (this DirSink) WriteFile(filename string, data []byte) error {
+	fullPath := filepath.Join(this.Dir, filename)
+	spicelog.Infof("Writing file %q", fullPath)
+	return os.WriteFile(fullPath, data, 0644)
+}
+
+// MemorySink keeps the generated files in memory, keyed by file name
+type MemorySink map[string][]byte
+
+func (this MemorySink) WriteFile(filename string, data []byte) error {
+	this[filename] = data
+	return nil
+}
+
+type Options struct {
+	//Source provides the tables to generate code for
+	Source SchemaAdapter
+	//Sink receives the generated files
+	Sink Sink
+	//Package is the package name of the generated files
+	Package string
+	//Include decides if code is generated for a table. When
+	//nil code is generated for every table.
+	Include func(tableName string) bool
+	//Concurrency is the maximum number of tables processed at
+	//once. Values less than one are treated as one.
+	Concurrency int
+	//KeepGoing processes every table even after a table fails
+	KeepGoing bool
+	//TemplateDir is a directory of *.tmpl files that replace
+	//or add to the builtin templates. When empty only the
+	//builtin templates are used.
+	TemplateDir string
+	//Emitters returns the names of the emitters in Registry to
+	//run for a table in addition to the builtin ones. When nil
+	//only the builtin emitters are run.
+	Emitters func(tableName string) []string
+	//Registry is where emitters are looked up. When nil
+	//DefaultEmitters is used.
+	Registry *EmitterRegistry
+	//StructTags configures the tags on the fields of the models
+	StructTags StructTags
+	//JSONNulls makes the MarshalJSON generated by the json
+	//emitter write null for columns that are not loaded or set
+	JSONNulls bool
+	//JSONNumbers makes the MarshalJSON generated by the json
+	//emitter write NUMERIC columns as JSON numbers
+	JSONNumbers bool
+	//Audit decides if changes to a table are written to
+	//AuditTable. When nil no table is audited.
+	Audit func(tableName string) bool
+	//AuditTable is the name of the audit table. When empty
+	//DefaultAuditTable is used.
+	AuditTable string
+	//NullValueTypes makes nullable columns use the Null types of
+	//the runtime instead of pointer fields
+	NullValueTypes bool
+	//NumericRounder is the name of the rounder used by the
+	//generated Validate. When empty DefaultNumericRounder is used.
+	NumericRounder string
+}
+
+type Result struct {
+	//Tables are the names of the tables code was generated for
+	Tables []string
+	//Selected is the number of tables included for processing, some
+	//of which may not have been processed if an error stopped the run
+	Selected int
+	//Skipped are the names of the tables excluded by Include
+	Skipped []string
+	//Files are the names of the files written to the sink
+	Files []string
+	//Errors are the tables that failed
+	Errors []TableError
+}
+
+// GenerateError is returned by Generate when one or more tables fail
+type GenerateError struct {
+	Errors []TableError
+}
+
+func (this GenerateError) Error() string {
+	return fmt.Sprintf("Failed processing %d tables, first error:%v",
+		len(this.Errors),
+		this.Errors[0])
+}
+
+// Generate reads the tables from opts.Source and writes the generated
+// code for each table to opts.Sink. All files of a table are rendered
+// before any of them are written.
+func Generate(ctx context.Context, opts Options) (Result, error) {
+	var result Result
+
+	if opts.Source == nil || opts.Sink == nil {
+		return result, fmt.Errorf("Source and Sink are required")
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	templates, err := LoadTemplates(opts.TemplateDir)
+	if err != nil {
+		return result, err
+	}
+
+	tables, err := opts.Source.Tables()
+	if err != nil {
+		return result, err
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Name() < tables[j].Name()
+	})
+
+	var selected []Table
+	for _, table := range tables {
+		if opts.Include != nil && !opts.Include(table.Name()) {
+			spicelog.Infof("Skipping table %q", table.Name())
+			result.Skipped = append(result.Skipped, table.Name())
+			continue
+		}
+		selected = append(selected, table)
+	}
+	result.Selected = len(selected)
+
+	var lock sync.Mutex
+	result.Errors = processTables(ctx, selected, concurrency, opts.KeepGoing, func(t Table) error {
+		spicelog.Infof("Processing table %q", t.Name())
+
+		me := NewModelEmitter()
+		me.Package = opts.Package
+		me.Templates = templates
+		me.StructTags = opts.StructTags
+		me.JSONNulls = opts.JSONNulls
+		me.JSONNumbers = opts.JSONNumbers
+		if opts.NullValueTypes {
+			me.UseNullValueTypes()
+		}
+		if opts.Audit != nil {
+			me.Audit = opts.Audit(t.Name())
+		}
+		if opts.AuditTable != "" {
+			me.AuditTable = opts.AuditTable
+		}
+		if opts.NumericRounder != "" {
+			me.NumericRounder = opts.NumericRounder
+		}
+		if opts.Registry != nil {
+			me.Registry = opts.Registry
+		}
+		if opts.Emitters != nil {
+			me.Emitters = opts.Emitters(t.Name())
+		}
+
+		files, err := me.Render(t)
+		if err != nil {
+			spicelog.Errorf("Error processing table %q:%v", t.Name(), err)
+			return err
+		}
+
+		var filenames []string
+		for filename, _ := range files {
+			filenames = append(filenames, filename)
+		}
+		sort.Strings(filenames)
+
+		lock.Lock()
+		defer lock.Unlock()
+		for _, filename := range filenames {
+			err = opts.Sink.WriteFile(filename, files[filename])
+			if err != nil {
+				spicelog.Errorf("Error processing table %q:%v", t.Name(), err)
+				return err
+			}
+			result.Files = append(result.Files, filename)
+		}
+		result.Tables = append(result.Tables, t.Name())
+		spicelog.Infof("Emitted model for table %q", t.Name())
+		return nil
+	})
+	sort.Strings(result.Files)
+	sort.Strings(result.Tables)
+
+	if len(result.Errors) != 0 {
+		return result, GenerateError{Errors: result.Errors}
+	}
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+	return result, nil
+}
@@ -0,0 +1,140 @@
+package sillyquill_gen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testTables(n int) []Table {
+	var result []Table
+	for i := 0; i != n; i++ {
+		result = append(result, &SnapshotTable{TableName: fmt.Sprintf("t%02d", i)})
+	}
+	return result
+}
+
+func TestProcessTablesBoundsConcurrency(t *testing.T) {
+	var lock sync.Mutex
+	var running, maxRunning, calls int
+	errs := processTables(context.Background(), testTables(20), 3, false, func(Table) error {
+		lock.Lock()
+		running++
+		calls++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+		return nil
+	})
+	if len(errs) != 0 {
+		t.Fatalf("got errors %v", errs)
+	}
+	if calls != 20 {
+		t.Errorf("got %d calls; want 20", calls)
+	}
+	if maxRunning > 3 {
+		t.Errorf("got %d concurrent calls; want at most 3", maxRunning)
+	}
+}
+
+func TestProcessTablesErrors(t *testing.T) {
+	failing := func(table Table) error {
+		switch table.Name() {
+		case "t03", "t07":
+			return fmt.Errorf("failed %s", table.Name())
+		}
+		return nil
+	}
+
+	errs := processTables(context.Background(), testTables(10), 1, true, failing)
+	if len(errs) != 2 || errs[0].TableName != "t03" || errs[1].TableName != "t07" {
+		t.Errorf("got %v with keep going", errs)
+	}
+
+	var calls int
+	errs = processTables(context.Background(), testTables(10), 1, false, func(table Table) error {
+		calls++
+		return failing(table)
+	})
+	if len(errs) != 1 || errs[0].TableName != "t03" {
+		t.Errorf("got %v when failing fast", errs)
+	}
+	if calls != 4 {
+		t.Errorf("got %d calls when failing fast; want 4", calls)
+	}
+}
+
+type tableSource []Table
+
+func (this tableSource) Tables() ([]Table, error) {
+	return this, nil
+}
+
+type brokenTable struct {
+	SnapshotTable
+}
+
+func (this *brokenTable) Columns() ([]Column, error) {
+	return nil, fmt.Errorf("no columns")
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := os.ReadFile("../gen_test/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := ParseDDL(string(src), "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var source tableSource
+	for _, v := range tables {
+		source = append(source, v)
+	}
+	source = append(source, &brokenTable{SnapshotTable{TableName: "broken"}})
+
+	sink := make(MemorySink)
+	result, err := Generate(context.Background(), Options{
+		Source:      source,
+		Sink:        sink,
+		Package:     "dal",
+		Concurrency: 4,
+		KeepGoing:   true,
+		Include: func(tableName string) bool {
+			return tableName != "wheels"
+		},
+	})
+	genErr, ok := err.(GenerateError)
+	if !ok || len(genErr.Errors) != 1 || genErr.Errors[0].TableName != "broken" {
+		t.Fatalf("got %v; want error for table broken", err)
+	}
+	if !reflect.DeepEqual(result.Skipped, []string{"wheels"}) {
+		t.Errorf("got skipped %v", result.Skipped)
+	}
+	if result.Selected != len(tables) {
+		t.Errorf("got %d selected; want %d", result.Selected, len(tables))
+	}
+	if len(result.Tables) != len(tables)-1 {
+		t.Errorf("got tables %v", result.Tables)
+	}
+	if len(result.Files) != len(sink) || len(sink) != 4*len(result.Tables) {
+		t.Errorf("got %d files and %d in sink", len(result.Files), len(sink))
+	}
+	if _, ok := sink["trucks_saver.go"]; !ok {
+		t.Errorf("trucks_saver.go not in %v", result.Files)
+	}
+	if _, ok := sink["wheels.go"]; ok {
+		t.Errorf("excluded table generated")
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package sillyquill_gen
 
 import "github.com/spiceworks/spicelog"
 import _ "github.com/lib/pq"
@@ -14,6 +14,10 @@ type Table interface {
 	ForeignKeys() ([]string, error)
 }
 
+type SchemaAdapter interface {
+	Tables() ([]Table, error)
+}
+
 type SqlDataType int
 
 const sqlUnknown = SqlDataType(0)
@@ -79,7 +83,7 @@ func (this *InformationSchemaColumn) StringToSqlDataType(v string) (SqlDataType,
 		//TIMESTAMPTZ - timestamp with time zone - unsupported
 
 		return SqlTimestamp, nil
-	case "TSVECTOR":
+	case "TSVECTOR", "JSON", "JSONB":
 		//Ignore these columns
 		return sqlUnknown, ErrSkipColumn
 	}
@@ -99,16 +103,32 @@ type Column interface {
 	IsUpdateTimestamp() bool
 }
 
+// numericColumn is implemented by columns that know the declared
+// precision and scale of a NUMERIC column. The precision is zero
+// when the column is unconstrained.
+type numericColumn interface {
+	NumericPrecision() (int, int)
+}
+
 type InformationSchemaAdapter struct {
 	TableSchema string
 	db          *sql.DB
 }
 
+func NewInformationSchemaAdapter(db *sql.DB, tableSchema string) *InformationSchemaAdapter {
+	return &InformationSchemaAdapter{
+		db:          db,
+		TableSchema: tableSchema,
+	}
+}
+
 type InformationSchemaColumn struct {
-	name     string
-	dataType SqlDataType
-	nullable bool
-	parent   *InformationSchemaTable
+	name      string
+	dataType  SqlDataType
+	nullable  bool
+	precision int
+	scale     int
+	parent    *InformationSchemaTable
 }
 
 func (this *InformationSchemaColumn) IsCreationTimestamp() bool {
@@ -131,6 +151,10 @@ func (this *InformationSchemaColumn) Nullable() bool {
 	return this.nullable
 }
 
+func (this *InformationSchemaColumn) NumericPrecision() (int, int) {
+	return this.precision, this.scale
+}
+
 type InformationSchemaTable struct {
 	name   string
 	parent *InformationSchemaAdapter
@@ -169,7 +193,10 @@ func (this *InformationSchemaTable) columnNamesWhereConstraintType(constraint_ty
 	and 
 		information_schema.table_constraints.table_name = $2
 	and 
-		constraint_type = $3`
+		constraint_type = $3
+	order by
+		information_schema.table_constraints.constraint_name,
+		column_name`
 
 	var uniques []string
 	var err error
@@ -203,13 +230,17 @@ func (this *InformationSchemaTable) Columns() ([]Column, error) {
 	const query = `Select column_name,
 	data_type,
 	is_nullable,
-	udt_name
+	udt_name,
+	coalesce(numeric_precision, 0),
+	coalesce(numeric_scale, 0)
 	 from 
 	information_schema.columns  
 	where 
 		table_name = $1
 	and 
-		table_schema = $2`
+		table_schema = $2
+	order by
+		ordinal_position`
 
 	rows, err := this.parent.db.Query(query, this.name, this.parent.TableSchema)
 	if err != nil {
@@ -223,7 +254,11 @@ func (this *InformationSchemaTable) Columns() ([]Column, error) {
 		var data_type string
 		var is_nullable string
 		var udt_name string
-		err := rows.Scan(&column_name, &data_type, &is_nullable, &udt_name)
+		var numeric_precision int
+		var numeric_scale int
+		err := rows.Scan(&column_name, &data_type, &is_nullable, &udt_name,
+			&numeric_precision,
+			&numeric_scale)
 		if err != nil {
 			return nil, err
 		}
@@ -246,6 +281,13 @@ func (this *InformationSchemaTable) Columns() ([]Column, error) {
 			return nil, err
 		}
 
+		//The precision of integer and floating point columns is
+		//reported in bits
+		if col.dataType == SqlNumeric {
+			col.precision = numeric_precision
+			col.scale = numeric_scale
+		}
+
 		switch is_nullable {
 		case "NO":
 			col.nullable = false
@@ -269,7 +311,7 @@ func (this *InformationSchemaTable) Columns() ([]Column, error) {
 }
 
 func (this *InformationSchemaAdapter) Tables() ([]Table, error) {
-	const query = "Select table_name from information_schema.tables where table_schema=$1"
+	const query = "Select table_name from information_schema.tables where table_schema=$1 order by table_name"
 
 	rows, err := this.db.Query(query, this.TableSchema)
 	if err != nil {
@@ -0,0 +1,68 @@
+package sillyquill_gen
+
+import "fmt"
+import "io"
+import "text/template"
+
+type ColumnLoader struct {
+	ColumnAnalyzerFunctionName  string
+	LoadManyFunctionName        string
+	LoadWithColumnsReceiverName string
+	FinderTypeName              string
+	FindFunctionName            string
+	PageFunctionName            string
+	DecodeCursorFunctionName    string
+	PageKeyColumnsName          string
+	//PageKey are the columns pages are ordered by, empty if the
+	//rows can not be paged
+	PageKey []ColumnizedField
+
+	TheColumnType       *ColumnType
+	TheColumnizedStruct *ColumnizedStruct
+	Template            *template.Template
+}
+
+func NewColumnLoaderFor(s *ColumnizedStruct,
+	columnInterfaces *ColumnType) *ColumnLoader {
+	this := new(ColumnLoader)
+	this.ColumnAnalyzerFunctionName = fmt.Sprintf("analyzeColumnsFor%s",
+		s.PluralModelName)
+
+	this.LoadWithColumnsReceiverName = fmt.Sprintf("loadWithColumns")
+	this.LoadManyFunctionName = fmt.Sprintf("LoadMany%s",
+		s.PluralModelName)
+	this.FinderTypeName = fmt.Sprintf("%sFinder", s.SingularModelName)
+	this.FindFunctionName = fmt.Sprintf("Find%s", s.PluralModelName)
+	this.PageFunctionName = fmt.Sprintf("Page%s", s.PluralModelName)
+	this.DecodeCursorFunctionName = fmt.Sprintf("Decode%sCursor", s.SingularModelName)
+	this.PageKeyColumnsName = fmt.Sprintf("%sPageKeyColumns", privatizeTypeName(s.PluralModelName))
+	//Paging requires a key that orders every row, NULL values
+	//would be skipped
+	if len(s.PrimaryKey) != 0 {
+		this.PageKey = s.PrimaryKey
+	} else if s.PreferredUnique != nil && !s.PreferredUnique.Nullable {
+		this.PageKey = []ColumnizedField{*s.PreferredUnique}
+	}
+	this.TheColumnType = columnInterfaces
+	this.TheColumnizedStruct = s
+
+	return this
+}
+
+func (this *ColumnLoader) Suffix() string {
+	return "_loader"
+}
+
+func (this *ColumnLoader) Imports() []string {
+	return []string{
+		"github.com/hydrogen18/sillyquill/rt",
+		"context",
+		"database/sql",
+		"bytes",
+		"fmt",
+	}
+}
+
+func (this *ColumnLoader) Emit(w io.Writer) error {
+	return this.Template.Execute(w, this)
+}
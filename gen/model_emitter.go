@@ -0,0 +1,516 @@
+package sillyquill_gen
+
+import "bytes"
+import "fmt"
+import "go/ast"
+import "go/format"
+import "go/parser"
+import "go/scanner"
+import "go/token"
+import "io"
+import "strconv"
+
+import "github.com/spiceworks/spicelog"
+import "reflect"
+import "time"
+import "strings"
+import "os"
+import "path/filepath"
+import "sort"
+import "github.com/hydrogen18/sillyquill/rt"
+
+type ModelEmitter struct {
+	TableNameToCodeName  func(string) string
+	ColumnNameToCodeName func(string) string
+	ColumnToDataType     func(Column) []interface{}
+	Tab                  string
+	Package              string
+	Templates            *Templates
+	//Emitters are the names of emitters in Registry that are
+	//run in addition to the builtin ones
+	Emitters   []string
+	Registry   *EmitterRegistry
+	StructTags StructTags
+	//JSONNulls makes the generated MarshalJSON emit null for
+	//columns that are neither loaded nor set instead of
+	//omitting them
+	JSONNulls bool
+	//JSONNumbers makes the generated MarshalJSON write NUMERIC
+	//columns as JSON numbers instead of strings
+	JSONNumbers bool
+	//Audit makes Save, Create and Delete write a row to
+	//AuditTable in the same transaction as the change
+	Audit      bool
+	AuditTable string
+	//NumericRounder is the name of the rounder of the dec package
+	//used by Validate, such as RoundHalfUp. RoundExact rejects
+	//values with more digits than the scale of the column.
+	NumericRounder string
+}
+
+// UseNullValueTypes makes nullable columns use the Null types of the
+// runtime such as sillyquill_rt.NullString instead of pointer fields
+func (this *ModelEmitter) UseNullValueTypes() {
+	this.ColumnToDataType = columnToNullValueDataType
+}
+
+func NewModelEmitter() *ModelEmitter {
+	return &ModelEmitter{
+		TableNameToCodeName:  UnderscoresToCamelCase,
+		ColumnNameToCodeName: UnderscoresToCamelCase,
+		ColumnToDataType:     columnToDataType,
+		Tab:                  "    ",
+		Templates:            builtinTemplates,
+		Registry:             DefaultEmitters,
+		AuditTable:           DefaultAuditTable,
+		NumericRounder:       DefaultNumericRounder,
+	}
+}
+
+func UnderscoresToCamelCase(v string) string {
+	var output []rune
+
+	var in_name bool
+	for _, c := range v {
+		if c == '_' && in_name {
+			in_name = false
+		} else if !in_name {
+			c = []rune(strings.ToUpper(string(c)))[0]
+			in_name = true
+			output = append(output, c)
+		} else {
+			output = append(output, c)
+		}
+	}
+
+	return string(output)
+}
+
+type panicWriter struct {
+	io.Writer
+	level int
+	tab   string
+}
+
+func (this *panicWriter) Write(data []byte) (int, error) {
+	n, err := this.Writer.Write(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return n, nil
+}
+
+func (this *panicWriter) indent() {
+	this.level++
+}
+func (this *panicWriter) deindent() {
+	this.level--
+	if this.level == -1 {
+		panic("negative indent")
+	}
+}
+
+func (this *panicWriter) fprintLn(fmtStr string, args ...interface{}) {
+	for i := this.level; i != 0; i-- {
+		fmt.Fprint(this, this.tab)
+	}
+	fmt.Fprintf(this, fmtStr, args...)
+	fmt.Fprint(this, "\n")
+}
+
+func tempModelNamer(v string) (string, string) {
+	name := UnderscoresToCamelCase(v)
+
+	var pluralName string
+	var singularName string
+	if name[len(name)-1] == 's' {
+		pluralName = name
+		singularName = pluralName[0 : len(pluralName)-1]
+	} else {
+		singularName = name
+		pluralName = name + "s"
+	}
+
+	return pluralName, singularName
+}
+
+// DefaultAuditTable is the table audited changes are written to
+const DefaultAuditTable = "audit_log"
+
+// DefaultNumericRounder is the rounder used by Validate, which is the
+// same as PostgreSQL rounds NUMERIC values
+const DefaultNumericRounder = "RoundHalfEven"
+
+// NumericRounders are the names of the rounders of the dec package
+// that NumericRounder can be set to
+var NumericRounders = []string{
+	"RoundExact",
+	"RoundDown",
+	"RoundUp",
+	"RoundHalfDown",
+	"RoundHalfUp",
+	"RoundHalfEven",
+	"RoundFloor",
+	"RoundCeil",
+}
+
+func isNumericRounder(name string) bool {
+	for _, v := range NumericRounders {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+type UnknownNumericRounderError struct {
+	Name string
+}
+
+func (this UnknownNumericRounderError) Error() string {
+	return fmt.Sprintf("Unknown numeric rounder %q, expected one of %v", this.Name, NumericRounders)
+}
+
+// AuditWithoutPrimaryKeyError is returned when an audited table has
+// no primary key to record in the audit table
+type AuditWithoutPrimaryKeyError struct {
+	TableName string
+}
+
+func (this AuditWithoutPrimaryKeyError) Error() string {
+	return fmt.Sprintf("Table %q is audited but has no primary key", this.TableName)
+}
+
+const generatedCodeHeader = "// Code generated by sillyquill. DO NOT EDIT."
+
+// importNames maps the path of imported packages to their name where
+// the name differs from the last element of the path
+var importNames = map[string]string{
+	"github.com/hydrogen18/sillyquill/rt": sillyquil_runtime_pkg_name,
+}
+
+func importName(importPath string) string {
+	name, ok := importNames[importPath]
+	if ok {
+		return name
+	}
+	return importPath[strings.LastIndex(importPath, "/")+1:]
+}
+
+type GeneratedCodeError struct {
+	TableName string
+	Filename  string
+	Line      int
+	Source    string
+	Err       error
+}
+
+func (this GeneratedCodeError) Error() string {
+	return fmt.Sprintf("Generated invalid code for table %q in file %q line %d %q:%v",
+		this.TableName,
+		this.Filename,
+		this.Line,
+		this.Source,
+		this.Err)
+}
+
+// formatSource removes unused imports from the generated source and
+// formats it the same as gofmt
+func formatSource(src []byte, filename string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	var decls []ast.Decl
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			decls = append(decls, decl)
+			continue
+		}
+
+		var specs []ast.Spec
+		for _, spec := range gen.Specs {
+			importPath, err := strconv.Unquote(spec.(*ast.ImportSpec).Path.Value)
+			if err != nil {
+				return nil, err
+			}
+			if used[importName(importPath)] {
+				specs = append(specs, spec)
+			}
+		}
+		if len(specs) != 0 {
+			gen.Specs = specs
+			decls = append(decls, gen)
+		}
+	}
+	file.Decls = decls
+
+	var buf bytes.Buffer
+	err = format.Node(&buf, fset, file)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// render returns the formatted source of the file for the emitter
+func (this *ModelEmitter) render(emitter CodeEmitter, tableName string, filename string) ([]byte, error) {
+	var buf bytes.Buffer
+	pw := &panicWriter{
+		Writer: &buf,
+		level:  0,
+		tab:    this.Tab,
+	}
+
+	pw.fprintLn(generatedCodeHeader)
+	pw.fprintLn("")
+	pw.fprintLn("package %s", this.Package)
+
+	imports := make(map[string]int)
+
+	emitterImports := emitter.Imports()
+	for _, v := range emitterImports {
+		imports[v] = 0
+	}
+
+	pw.fprintLn("")
+
+	var sortedImports []string
+	for v, _ := range imports {
+		sortedImports = append(sortedImports, v)
+	}
+	sort.Strings(sortedImports)
+	for _, v := range sortedImports {
+		pw.fprintLn("import %q", v)
+	}
+	pw.fprintLn("")
+
+	err := emitter.Emit(pw)
+	if err != nil {
+		return nil, err
+	}
+
+	src := buf.Bytes()
+	formatted, err := formatSource(src, filename)
+	if err != nil {
+		codeErr := GeneratedCodeError{
+			TableName: tableName,
+			Filename:  filename,
+			Err:       err,
+		}
+		if list, ok := err.(scanner.ErrorList); ok && len(list) != 0 {
+			codeErr.Line = list[0].Pos.Line
+			lines := strings.Split(string(src), "\n")
+			if codeErr.Line > 0 && codeErr.Line <= len(lines) {
+				codeErr.Source = strings.TrimSpace(lines[codeErr.Line-1])
+			}
+		}
+		return nil, codeErr
+	}
+	return formatted, nil
+}
+
+// Render returns the formatted source of each file generated for
+// the table, keyed by file name
+func (this *ModelEmitter) Render(table Table) (map[string][]byte, error) {
+
+	columnizedStruct, err := NewColumnizedStruct(table,
+		tempModelNamer,
+		this.ColumnNameToCodeName,
+		this.ColumnToDataType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range columnizedStruct.Fields {
+		field := &columnizedStruct.Fields[i]
+		columnName := columnizedStruct.Columns[i].Name()
+		field.Tag, err = this.StructTags.Tag(table.Name(), columnName)
+		if err != nil {
+			return nil, err
+		}
+		field.JSONName, err = this.StructTags.Name("json", table.Name(), columnName, field.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	columnizedStruct.ExcludedTag = this.StructTags.ExcludedTag()
+	columnizedStruct.JSONNulls = this.JSONNulls
+	columnizedStruct.JSONNumbers = this.JSONNumbers
+	columnizedStruct.Audit = this.Audit
+	columnizedStruct.AuditTable = this.AuditTable
+	if this.Audit && len(columnizedStruct.PrimaryKey) == 0 {
+		return nil, AuditWithoutPrimaryKeyError{TableName: table.Name()}
+	}
+	columnizedStruct.NumericRounder = this.NumericRounder
+	if !isNumericRounder(this.NumericRounder) {
+		return nil, UnknownNumericRounderError{Name: this.NumericRounder}
+	}
+
+	columnType := NewColumnType(columnizedStruct)
+	columnizedStruct.TheColumnType = columnType
+
+	columnLoader := NewColumnLoaderFor(columnizedStruct,
+		columnType)
+
+	columnSaver := NewColumnSaverFor(columnizedStruct,
+		columnType)
+
+	columnizedStruct.Template = this.Templates.Lookup(ModelTemplateName)
+	columnType.Template = this.Templates.Lookup(ColumnsTemplateName)
+	columnLoader.Template = this.Templates.Lookup(LoaderTemplateName)
+	columnSaver.Template = this.Templates.Lookup(SaverTemplateName)
+
+	emitters := []CodeEmitter{
+		columnizedStruct,
+		columnType,
+		columnLoader,
+		columnSaver,
+	}
+	for _, name := range this.Templates.Additional() {
+		emitters = append(emitters, &TemplateEmitter{
+			Template: this.Templates.Lookup(name),
+			Data:     columnizedStruct,
+		})
+	}
+	for _, name := range this.Emitters {
+		factory, ok := this.Registry.Lookup(name)
+		if !ok {
+			return nil, UnknownEmitterError{Name: name}
+		}
+		emitter, err := factory(table, columnizedStruct, this.Templates)
+		if err != nil {
+			return nil, err
+		}
+		emitters = append(emitters, emitter)
+	}
+
+	files := make(map[string][]byte)
+	for _, emitter := range emitters {
+		filename := fmt.Sprintf("%s%s.go",
+			columnizedStruct.TableName,
+			emitter.Suffix())
+		if _, ok := files[filename]; ok {
+			return nil, fmt.Errorf("More than one emitter produces %q", filename)
+		}
+		files[filename], err = this.render(emitter, columnizedStruct.TableName, filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func (this *ModelEmitter) Emit(table Table, outputPath string) error {
+	files, err := this.Render(table)
+	if err != nil {
+		return err
+	}
+
+	//Nothing is written unless every file of the table rendered
+	var filenames []string
+	for filename, _ := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		fullPath := filepath.Join(outputPath, filename)
+		spicelog.Infof("Writing file %q", fullPath)
+		err = os.WriteFile(fullPath, files[filename], 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const sillyquil_runtime_pkg_name = "sillyquill_rt"
+
+func columnToDataType(c Column) []interface{} {
+	dt := c.DataType()
+
+	var stub []interface{}
+
+	if c.Nullable() {
+		stub = []interface{}{reflect.Ptr}
+	}
+
+	switch dt {
+	case SqlSmallInt:
+		return append(stub, reflect.Int16)
+	case SqlInt:
+		return append(stub, reflect.Int32)
+	case SqlBigInt:
+		return append(stub, reflect.Int64)
+	case SqlBoolean:
+		return append(stub, reflect.Bool)
+	case SqlTimestamp, SqlDate:
+		return append(stub, reflect.Struct, time.Time{})
+	case SqlVarChar, SqlText:
+		return append(stub, reflect.String)
+	case SqlFloat64:
+		return append(stub, reflect.Float64)
+	case SqlByteArray:
+		return append(stub, reflect.Slice, reflect.Uint8)
+	case SqlReal:
+		return append(stub, reflect.Float32)
+	case SqlNumeric:
+		//NullNumeric keeps the type of pointer fields the same as before
+		//it had a Valid field
+		if c.Nullable() {
+			return append(stub, reflect.Struct, sillyquill_rt.NullNumeric{})
+		}
+		return append(stub, reflect.Struct, sillyquill_rt.Numeric{})
+	}
+
+	return nil
+}
+
+// columnToNullValueDataType is the same as columnToDataType except
+// that nullable columns use the Null types of the runtime instead
+// of a pointer
+func columnToNullValueDataType(c Column) []interface{} {
+	if !c.Nullable() {
+		return columnToDataType(c)
+	}
+
+	switch c.DataType() {
+	case SqlSmallInt:
+		return []interface{}{reflect.Struct, sillyquill_rt.NullInt16{}}
+	case SqlInt:
+		return []interface{}{reflect.Struct, sillyquill_rt.NullInt32{}}
+	case SqlBigInt:
+		return []interface{}{reflect.Struct, sillyquill_rt.NullInt64{}}
+	case SqlBoolean:
+		return []interface{}{reflect.Struct, sillyquill_rt.NullBool{}}
+	case SqlTimestamp, SqlDate:
+		return []interface{}{reflect.Struct, sillyquill_rt.NullTime{}}
+	case SqlVarChar, SqlText:
+		return []interface{}{reflect.Struct, sillyquill_rt.NullString{}}
+	case SqlFloat64:
+		return []interface{}{reflect.Struct, sillyquill_rt.NullFloat64{}}
+	case SqlByteArray:
+		return []interface{}{reflect.Struct, sillyquill_rt.NullBytes{}}
+	case SqlReal:
+		return []interface{}{reflect.Struct, sillyquill_rt.NullFloat32{}}
+	case SqlNumeric:
+		return []interface{}{reflect.Struct, sillyquill_rt.NullNumeric{}}
+	}
+
+	return nil
+}
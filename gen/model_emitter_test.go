@@ -0,0 +1,365 @@
+package sillyquill_gen
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func emitGenTestSchema(t *testing.T, outputPath string) {
+	src, err := os.ReadFile("../gen_test/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := ParseDDL(string(src), "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, table := range tables {
+		me := NewModelEmitter()
+		me.Package = "dal"
+		err = me.Emit(table, outputPath)
+		if err != nil {
+			t.Fatalf("Emit %q:%v", table.Name(), err)
+		}
+	}
+}
+
+// generatedFileNames returns the sorted names of the files in dir
+func generatedFileNames(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, v := range entries {
+		names = append(names, v.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestModelEmitterDeterministic(t *testing.T) {
+	first := t.TempDir()
+	emitGenTestSchema(t, first)
+
+	names := generatedFileNames(t, first)
+	if len(names) == 0 {
+		t.Fatal("no files generated")
+	}
+
+	for run := 0; run != 5; run++ {
+		second := t.TempDir()
+		emitGenTestSchema(t, second)
+
+		if secondNames := generatedFileNames(t, second); !reflect.DeepEqual(names, secondNames) {
+			t.Fatalf("got files %v; want %v", secondNames, names)
+		}
+		for _, name := range names {
+			expected, err := os.ReadFile(filepath.Join(first, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := os.ReadFile(filepath.Join(second, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(expected, actual) {
+				t.Fatalf("output of %q differs between runs", name)
+			}
+		}
+	}
+}
+
+func TestFormatSourcePrunesImports(t *testing.T) {
+	const src = `package dal
+import "bytes"
+import "fmt"
+import "github.com/hydrogen18/sillyquill/rt"
+func f() error {
+return sillyquill_rt.UnknownColumnError{Index: 1,Name: fmt.Sprint(1)}
+}
+`
+	const expected = `package dal
+
+import "fmt"
+import "github.com/hydrogen18/sillyquill/rt"
+
+func f() error {
+	return sillyquill_rt.UnknownColumnError{Index: 1, Name: fmt.Sprint(1)}
+}
+`
+	actual, err := formatSource([]byte(src), "f.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != expected {
+		t.Errorf("got\n%s\nwant\n%s", actual, expected)
+	}
+}
+
+type brokenEmitter struct{}
+
+func (brokenEmitter) Emit(w io.Writer) error {
+	_, err := io.WriteString(w, "func f() {\nreturn (\n}\n")
+	return err
+}
+
+func (brokenEmitter) Imports() []string {
+	return nil
+}
+
+func (brokenEmitter) Suffix() string {
+	return ""
+}
+
+func TestRenderReportsInvalidCode(t *testing.T) {
+	me := NewModelEmitter()
+	me.Package = "dal"
+	_, err := me.render(brokenEmitter{}, "trucks", "trucks.go")
+	codeErr, ok := err.(GeneratedCodeError)
+	if !ok {
+		t.Fatalf("got %v; want %T", err, codeErr)
+	}
+	if codeErr.TableName != "trucks" || codeErr.Line != 8 || codeErr.Source != "}" {
+		t.Errorf("got %+v", codeErr)
+	}
+}
+
+func TestModelDirtyTracking(t *testing.T) {
+	me := NewModelEmitter()
+	me.Package = "dal"
+	files, err := me.Render(carsTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for filename, expected := range map[string][]string{
+		"cars.go": {
+			"original struct {\n\t\tId   int32\n\t\tMake string\n\t\tnull [2]bool\n\t}",
+			"this.markSet(Cars.Make)",
+			"func (this *Car) Changes() map[CarColumn][2]interface{} {",
+			"func (this *Car) HasChanges() bool {",
+			"func (this *Car) Revert() {",
+			"if !this.HasChanges() {\n\t\treturn nil\n\t}",
+		},
+		"cars_columns.go": {
+			"var CarsColumns = []CarColumn{\n\tCars.Id,\n\tCars.Make,\n}",
+			"if v {\n\t\tm.original.Make = m.Make\n\t}",
+			"return m.original.Make != v",
+			"if m.IsLoaded.Make {\n\t\tv := m.original.Make\n\t\tm.Make = v",
+		},
+	} {
+		for _, v := range expected {
+			if !bytes.Contains(files[filename], []byte(v)) {
+				t.Errorf("%s not in %s:\n%s", v, filename, files[filename])
+			}
+		}
+	}
+}
+
+func TestModelAudit(t *testing.T) {
+	me := NewModelEmitter()
+	me.Package = "dal"
+	files, err := me.Render(carsTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(files["cars.go"], []byte("writeAudit")) {
+		t.Errorf("audit code generated without audit")
+	}
+
+	me.Audit = true
+	me.AuditTable = "history"
+	files, err = me.Render(carsTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		`return sillyquill_rt.WriteAudit(ctx, db, "history", entry)`,
+		"for _, v := range CarsPrimaryKeyColumns {",
+		"return this.writeAudit(ctx, tx, primaryKey, sillyquill_rt.AuditUpdate, changes)",
+		"return this.writeAudit(ctx, tx, primaryKey, sillyquill_rt.AuditInsert, changes)",
+		"return this.writeAudit(ctx, tx, primaryKey, sillyquill_rt.AuditDelete, changes)",
+	} {
+		if !bytes.Contains(files["cars.go"], []byte(v)) {
+			t.Errorf("%s not in:\n%s", v, files["cars.go"])
+		}
+	}
+
+	_, err = me.Render(&SnapshotTable{
+		TableName:     "events",
+		UniqueColumns: []string{"id"},
+		TableColumns: []*SnapshotColumn{
+			{ColumnName: "id", SqlType: SqlInt},
+		},
+	})
+	if v, ok := err.(AuditWithoutPrimaryKeyError); !ok || v.TableName != "events" {
+		t.Errorf("got %v; want AuditWithoutPrimaryKeyError", err)
+	}
+}
+
+func TestModelRowLocks(t *testing.T) {
+	me := NewModelEmitter()
+	me.Package = "dal"
+	files, err := me.Render(carsTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for filename, expected := range map[string][]string{
+		"cars.go": {
+			"func (this *Car) ReloadForUpdate(db sillyquill_rt.Executor, columns ...CarColumn) error {",
+			"func (this *Car) ReloadForShare(db sillyquill_rt.Executor, columns ...CarColumn) error {",
+			"err := sillyquill_rt.RequireTx(db)",
+		},
+		"cars_loader.go": {
+			"func FindCars() *CarFinder {",
+			"func (this *CarFinder) ForUpdate() *CarFinder {",
+			"func (this *CarFinder) SkipLocked() *CarFinder {",
+			"func (this *CarFinder) NoWait() *CarFinder {",
+			"func (this *CarFinder) Load(db sillyquill_rt.Executor, columns ...CarColumn) (CarList, error) {",
+			"(&buf).WriteString(lockClause)",
+		},
+	} {
+		for _, v := range expected {
+			if !bytes.Contains(files[filename], []byte(v)) {
+				t.Errorf("%s not in %s:\n%s", v, filename, files[filename])
+			}
+		}
+	}
+}
+
+func TestModelPaging(t *testing.T) {
+	me := NewModelEmitter()
+	me.Package = "dal"
+	files, err := me.Render(carsTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"var carsPageKeyColumns = CarColumnList{\n\tCars.Id,\n}",
+		"func PageCars(db sillyquill_rt.Executor, secret []byte, after *Car, limit int, columns ...CarColumn) (CarList, string, error) {",
+		"func DecodeCarCursor(secret []byte, cursor string) (*Car, error) {",
+	} {
+		if !bytes.Contains(files["cars_loader.go"], []byte(v)) {
+			t.Errorf("%s not in:\n%s", v, files["cars_loader.go"])
+		}
+	}
+
+	//Without a key that orders every row there is nothing to page on
+	files, err = me.Render(&SnapshotTable{
+		TableName:     "events",
+		UniqueColumns: []string{"name"},
+		TableColumns: []*SnapshotColumn{
+			{ColumnName: "name", SqlType: SqlText, IsNullable: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(files["events_loader.go"], []byte("PageEvents")) {
+		t.Errorf("paging generated for nullable unique column")
+	}
+}
+
+func TestModelNullValueTypes(t *testing.T) {
+	table := &SnapshotTable{
+		TableName:         "trucks",
+		PrimaryKeyColumns: []string{"id"},
+		TableColumns: []*SnapshotColumn{
+			{ColumnName: "id", SqlType: SqlInt},
+			{ColumnName: "make", SqlType: SqlText, IsNullable: true},
+			{ColumnName: "updated_at", SqlType: SqlTimestamp, IsNullable: true},
+		},
+	}
+	me := NewModelEmitter()
+	me.Package = "dal"
+	files, err := me.Render(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(files["trucks.go"], []byte("Make      *string")) {
+		t.Errorf("got\n%s", files["trucks.go"])
+	}
+
+	me.UseNullValueTypes()
+	files, err = me.Render(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for filename, expected := range map[string][]string{
+		"trucks.go": {
+			"Make      sillyquill_rt.NullString",
+			"UpdatedAt sillyquill_rt.NullTime",
+			"v.Time = v.Time.UTC()",
+			"this.SetUpdatedAt(sillyquill_rt.NullTime{Time: now, Valid: true})",
+		},
+		"trucks_columns.go": {
+			"if m.Make.Valid {\n\t\treturn m.Make.String\n\t}\n\treturn nil",
+			"m.original.null[1] = v && !m.Make.Valid",
+			"if isNull := !m.Make.Valid; isNull || m.original.null[1] {",
+			"if m.IsLoaded.Make && !m.original.null[1] {\n\t\tv := m.original.Make\n\t\tm.Make = sillyquill_rt.NullString{String: v, Valid: true}",
+		},
+	} {
+		for _, v := range expected {
+			if !bytes.Contains(files[filename], []byte(v)) {
+				t.Errorf("%s not in %s:\n%s", v, filename, files[filename])
+			}
+		}
+	}
+}
+
+func TestModelValidate(t *testing.T) {
+	table := &SnapshotTable{
+		TableName:         "prices",
+		PrimaryKeyColumns: []string{"id"},
+		TableColumns: []*SnapshotColumn{
+			{ColumnName: "id", SqlType: SqlInt},
+			{ColumnName: "amount", SqlType: SqlNumeric, Precision: 8, Scale: 2},
+			{ColumnName: "discount", SqlType: SqlNumeric, IsNullable: true, Precision: 4, Scale: 3},
+			{ColumnName: "weight", SqlType: SqlNumeric},
+		},
+	}
+	me := NewModelEmitter()
+	me.Package = "dal"
+	files, err := me.Render(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`"github.com/hydrogen18/sillyquill/dec"`,
+		"func (this *Price) Validate() error {",
+		`err := sillyquill_rt.FitNumeric("amount", &this.Amount, 8, 2, dec.RoundHalfEven)`,
+		"Discount *sillyquill_rt.NullNumeric",
+		"if this.IsSet.Discount && this.Discount != nil && this.Discount.Valid {",
+		`err := sillyquill_rt.FitNumeric("discount", &this.Discount.Numeric, 4, 3, dec.RoundHalfEven)`,
+	} {
+		if !bytes.Contains(files["prices.go"], []byte(expected)) {
+			t.Errorf("%s not in\n%s", expected, files["prices.go"])
+		}
+	}
+	if bytes.Contains(files["prices.go"], []byte(`"weight"`)) {
+		t.Errorf("unconstrained column validated:\n%s", files["prices.go"])
+	}
+
+	me.UseNullValueTypes()
+	me.NumericRounder = "RoundExact"
+	files, err = me.Render(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `err := sillyquill_rt.FitNumeric("discount", &this.Discount.Numeric, 4, 3, dec.RoundExact)`
+	if !bytes.Contains(files["prices.go"], []byte(expected)) {
+		t.Errorf("%s not in\n%s", expected, files["prices.go"])
+	}
+
+	me.NumericRounder = "RoundNearest"
+	_, err = me.Render(table)
+	if _, ok := err.(UnknownNumericRounderError); !ok {
+		t.Errorf("got %v; want UnknownNumericRounderError", err)
+	}
+}
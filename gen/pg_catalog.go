@@ -0,0 +1,526 @@
+package sillyquill_gen
+
+import "github.com/spiceworks/spicelog"
+import _ "github.com/lib/pq"
+import "database/sql"
+
+// PgCatalogAdapter reads the schema directly from the pg_catalog
+// tables. Unlike InformationSchemaAdapter the entire schema is
+// loaded up front by Tables() in a fixed number of queries.
+type PgCatalogAdapter struct {
+	TableSchema string
+	db          *sql.DB
+}
+
+func NewPgCatalogAdapter(db *sql.DB, tableSchema string) *PgCatalogAdapter {
+	return &PgCatalogAdapter{
+		db:          db,
+		TableSchema: tableSchema,
+	}
+}
+
+type PgCatalogColumn struct {
+	name       string
+	dataType   SqlDataType
+	isArray    bool
+	nullable   bool
+	comment    string
+	enumLabels []string
+	precision  int
+	scale      int
+	parent     *PgCatalogTable
+}
+
+func (this *PgCatalogColumn) IsCreationTimestamp() bool {
+	return this.Name() == "created_at" && this.DataType() == SqlTimestamp
+}
+
+func (this *PgCatalogColumn) IsUpdateTimestamp() bool {
+	return this.Name() == "updated_at" && this.DataType() == SqlTimestamp
+}
+
+func (this *PgCatalogColumn) Name() string {
+	return this.name
+}
+
+func (this *PgCatalogColumn) DataType() SqlDataType {
+	return this.dataType
+}
+
+func (this *PgCatalogColumn) Nullable() bool {
+	return this.nullable
+}
+
+// Comment returns the comment set with COMMENT ON COLUMN, if any
+func (this *PgCatalogColumn) Comment() string {
+	return this.comment
+}
+
+// EnumLabels returns the labels of the enum type of the column
+// in sort order. It is empty for columns that are not enums.
+func (this *PgCatalogColumn) EnumLabels() []string {
+	return this.enumLabels
+}
+
+func (this *PgCatalogColumn) NumericPrecision() (int, int) {
+	return this.precision, this.scale
+}
+
+// IsArray reports if the column is an array, in which case DataType
+// is the type of its elements, or zero if that is not supported
+func (this *PgCatalogColumn) IsArray() bool {
+	return this.isArray
+}
+
+// numericTypmod decodes the precision and scale of a NUMERIC column
+// from its type modifier, which is -1 when unconstrained
+func numericTypmod(typmod int32) (int, int) {
+	if typmod < 4 {
+		return 0, 0
+	}
+	typmod -= 4
+	return int((typmod >> 16) & 0xffff), int(typmod & 0xffff)
+}
+
+// PgCatalogConstraint is a constraint that is not used by the
+// generated models, such as an exclusion constraint
+type PgCatalogConstraint struct {
+	Name    string
+	Columns []string
+}
+
+type PgCatalogTable struct {
+	name         string
+	columns      []Column
+	arrayColumns []*PgCatalogColumn
+	primaryKey   []string
+	unique       []string
+	foreignKeys  []string
+	exclusion    []PgCatalogConstraint
+	parent       *PgCatalogAdapter
+}
+
+func (this *PgCatalogTable) Name() string {
+	return this.name
+}
+
+func (this *PgCatalogTable) Columns() ([]Column, error) {
+	return this.columns, nil
+}
+
+func (this *PgCatalogTable) PrimaryKey() ([]string, error) {
+	return this.primaryKey, nil
+}
+
+func (this *PgCatalogTable) Unique() ([]string, error) {
+	return this.unique, nil
+}
+
+func (this *PgCatalogTable) ForeignKeys() ([]string, error) {
+	return this.foreignKeys, nil
+}
+
+// ArrayColumns returns the array columns of the table. They are not
+// returned by Columns since the models have no type for them.
+func (this *PgCatalogTable) ArrayColumns() []*PgCatalogColumn {
+	return this.arrayColumns
+}
+
+// ExclusionConstraints returns the exclusion constraints of the table
+// with the columns they use. Columns used through an expression are
+// not included.
+func (this *PgCatalogTable) ExclusionConstraints() []PgCatalogConstraint {
+	return this.exclusion
+}
+
+func pgTypeToSqlDataType(typname string, tableName string, columnName string) (SqlDataType, error) {
+	switch typname {
+	case "float4":
+		return SqlReal, nil
+	case "int2":
+		return SqlSmallInt, nil
+	case "int4":
+		return SqlInt, nil
+	case "int8":
+		return SqlBigInt, nil
+	case "bool":
+		return SqlBoolean, nil
+	case "varchar":
+		return SqlVarChar, nil
+	case "text":
+		return SqlText, nil
+	case "bytea":
+		return SqlByteArray, nil
+	case "float8":
+		return SqlFloat64, nil
+	case "date":
+		return SqlDate, nil
+	case "numeric":
+		return SqlNumeric, nil
+	case "timestamp":
+		//timestamptz is unsupported, the same as InformationSchemaAdapter
+		return SqlTimestamp, nil
+	case "tsvector", "json", "jsonb":
+		//Ignore these columns
+		return sqlUnknown, ErrSkipColumn
+	}
+
+	return sqlUnknown, NoSuchDataTypeError{
+		ColumnName:  columnName,
+		TableName:   tableName,
+		SqlTypeName: typname,
+	}
+}
+
+// writableRelkinds are the relkinds of pg_class that models are
+// generated for, ordinary and partitioned tables
+var writableRelkinds = map[string]bool{"r": true, "p": true}
+
+var relkindNames = map[string]string{
+	"v": "view",
+	"m": "materialized view",
+	"f": "foreign table",
+}
+
+func (this *PgCatalogAdapter) Tables() ([]Table, error) {
+	//Partitions are skipped, the model is generated for the
+	//partitioned table only. Views, materialized views and foreign
+	//tables are logged and skipped since the models write to every
+	//table they are generated for.
+	const tablesQuery = `Select
+		c.oid,
+		c.relname,
+		c.relkind
+	from
+		pg_catalog.pg_class c
+	join
+		pg_catalog.pg_namespace n
+	on
+		n.oid = c.relnamespace
+	where
+		n.nspname = $1
+	and
+		c.relkind in ('r','p','v','m','f')
+	and
+		not c.relispartition
+	order by
+		c.relname`
+
+	rows, err := this.db.Query(tablesQuery, this.TableSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Table
+	byOid := make(map[uint32]*PgCatalogTable)
+	for rows.Next() {
+		var oid uint32
+		var relname string
+		var relkind string
+		err = rows.Scan(&oid, &relname, &relkind)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if !writableRelkinds[relkind] {
+			spicelog.Infof("Skipping %s %q", relkindNames[relkind], relname)
+			continue
+		}
+
+		t := &PgCatalogTable{
+			name:   relname,
+			parent: this,
+		}
+		byOid[oid] = t
+		results = append(results, t)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	enumLabels, err := this.enumLabels()
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.loadColumns(byOid, enumLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	err = this.loadConstraints(byOid)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (this *PgCatalogAdapter) enumLabels() (map[uint32][]string, error) {
+	const query = `Select
+		e.enumtypid,
+		e.enumlabel
+	from
+		pg_catalog.pg_enum e
+	order by
+		e.enumtypid,
+		e.enumsortorder`
+
+	rows, err := this.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint32][]string)
+	for rows.Next() {
+		var typid uint32
+		var label string
+		err = rows.Scan(&typid, &label)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		result[typid] = append(result[typid], label)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return result, nil
+}
+
+func (this *PgCatalogAdapter) loadColumns(byOid map[uint32]*PgCatalogTable, enumLabels map[uint32][]string) error {
+	//Domains are resolved to their base type and type modifier. The
+	//type of an array column is that of its elements, found through
+	//typelem, and its dimensions are not checked
+	const query = `Select
+		a.attrelid,
+		a.attname,
+		a.attnotnull,
+		coalesce(et.oid, bt.oid, t.oid),
+		coalesce(et.typname, bt.typname, t.typname),
+		coalesce(et.typtype, bt.typtype, t.typtype),
+		et.oid is not null,
+		case when t.typtype = 'd' then t.typtypmod else a.atttypmod end,
+		coalesce(col_description(a.attrelid, a.attnum), '')
+	from
+		pg_catalog.pg_attribute a
+	join
+		pg_catalog.pg_class c
+	on
+		c.oid = a.attrelid
+	join
+		pg_catalog.pg_namespace n
+	on
+		n.oid = c.relnamespace
+	join
+		pg_catalog.pg_type t
+	on
+		t.oid = a.atttypid
+	left join
+		pg_catalog.pg_type bt
+	on
+		t.typtype = 'd'
+	and
+		bt.oid = t.typbasetype
+	left join
+		pg_catalog.pg_type et
+	on
+		coalesce(bt.typcategory, t.typcategory) = 'A'
+	and
+		et.oid = coalesce(bt.typelem, t.typelem)
+	where
+		n.nspname = $1
+	and
+		a.attnum > 0
+	and
+		not a.attisdropped
+	order by
+		a.attrelid,
+		a.attnum`
+
+	rows, err := this.db.Query(query, this.TableSchema)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var attrelid uint32
+		var attname string
+		var attnotnull bool
+		var typid uint32
+		var typname string
+		var typtype string
+		var isArray bool
+		var typmod int32
+		var comment string
+		err = rows.Scan(&attrelid,
+			&attname,
+			&attnotnull,
+			&typid,
+			&typname,
+			&typtype,
+			&isArray,
+			&typmod,
+			&comment)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		table, ok := byOid[attrelid]
+		if !ok {
+			continue
+		}
+
+		col := &PgCatalogColumn{}
+		col.parent = table
+		col.name = attname
+		col.nullable = !attnotnull
+		col.comment = comment
+		col.isArray = isArray
+
+		if typtype == "e" {
+			//Enums are handled as strings, the database
+			//does the validation of the labels
+			col.dataType = SqlText
+			col.enumLabels = enumLabels[typid]
+		} else {
+			col.dataType, err = pgTypeToSqlDataType(typname, table.name, attname)
+			if err != nil && !isArray {
+				if err == ErrSkipColumn {
+					spicelog.Warningf("Skipping column %q of table %q type %q",
+						attname, table.name, typname)
+					continue
+				}
+				rows.Close()
+				return err
+			}
+			if col.dataType == SqlNumeric {
+				col.precision, col.scale = numericTypmod(typmod)
+			}
+		}
+
+		if isArray {
+			//The models have no array types, so the column is
+			//skipped even when the type of its elements is known
+			spicelog.Warningf("Skipping column %q of table %q, arrays of %q are not supported",
+				attname, table.name, typname)
+			table.arrayColumns = append(table.arrayColumns, col)
+			continue
+		}
+		table.columns = append(table.columns, col)
+	}
+
+	if rows.Err() != nil {
+		return rows.Err()
+	}
+	return nil
+}
+
+func (this *PgCatalogAdapter) loadConstraints(byOid map[uint32]*PgCatalogTable) error {
+	//Only single column unique constraints identify a row, so
+	//multi column unique constraints are not reported by Unique().
+	//The columns of a foreign key are those of conkey, the
+	//referencing side. Exclusion constraints do not identify a row
+	//either, they are logged and returned by ExclusionConstraints().
+	const query = `Select
+		con.conrelid,
+		con.contype,
+		con.conname,
+		coalesce(a.attname, '')
+	from
+		pg_catalog.pg_constraint con
+	join
+		pg_catalog.pg_namespace n
+	on
+		n.oid = con.connamespace
+	cross join lateral
+		unnest(con.conkey) with ordinality as k(attnum, position)
+	left join
+		pg_catalog.pg_attribute a
+	on
+		a.attrelid = con.conrelid
+	and
+		a.attnum = k.attnum
+	where
+		n.nspname = $1
+	and
+		con.contype in ('p','u','f','x')
+	order by
+		con.conrelid,
+		con.conname,
+		k.position`
+
+	rows, err := this.db.Query(query, this.TableSchema)
+	if err != nil {
+		return err
+	}
+
+	uniqueConstraints := make(map[*PgCatalogTable][]string)
+	uniqueColumns := make(map[*PgCatalogTable][]string)
+	for rows.Next() {
+		var conrelid uint32
+		var contype string
+		var conname string
+		var attname string
+		err = rows.Scan(&conrelid, &contype, &conname, &attname)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		table, ok := byOid[conrelid]
+		if !ok {
+			continue
+		}
+
+		switch contype {
+		case "p":
+			table.primaryKey = append(table.primaryKey, attname)
+		case "u":
+			uniqueConstraints[table] = append(uniqueConstraints[table], conname)
+			uniqueColumns[table] = append(uniqueColumns[table], attname)
+		case "f":
+			table.foreignKeys = append(table.foreignKeys, attname)
+		case "x":
+			last := len(table.exclusion) - 1
+			if last < 0 || table.exclusion[last].Name != conname {
+				table.exclusion = append(table.exclusion, PgCatalogConstraint{Name: conname})
+				last++
+			}
+			//an element that is an expression has no column
+			if attname != "" {
+				table.exclusion[last].Columns = append(table.exclusion[last].Columns, attname)
+			}
+		}
+	}
+
+	if rows.Err() != nil {
+		return rows.Err()
+	}
+	for _, table := range byOid {
+		for _, constraint := range table.exclusion {
+			spicelog.Infof("Table %q has exclusion constraint %q on %v, it is not used to identify rows",
+				table.name, constraint.Name, constraint.Columns)
+		}
+	}
+	for table, constraints := range uniqueConstraints {
+		table.unique = singleColumnConstraints(constraints, uniqueColumns[table])
+	}
+	return nil
+}
+
+// singleColumnConstraints returns the columns of the constraints that
+// have only one column, given the constraint of each column ordered by
+// constraint
+func singleColumnConstraints(constraints []string, columns []string) []string {
+	var result []string
+	for i := range columns {
+		if (i == 0 || constraints[i-1] != constraints[i]) &&
+			(i+1 == len(columns) || constraints[i+1] != constraints[i]) {
+			result = append(result, columns[i])
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package sillyquill_gen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPgTypeToSqlDataType(t *testing.T) {
+	expected := map[string]SqlDataType{
+		"float4":    SqlReal,
+		"int2":      SqlSmallInt,
+		"int4":      SqlInt,
+		"int8":      SqlBigInt,
+		"bool":      SqlBoolean,
+		"varchar":   SqlVarChar,
+		"text":      SqlText,
+		"bytea":     SqlByteArray,
+		"float8":    SqlFloat64,
+		"date":      SqlDate,
+		"numeric":   SqlNumeric,
+		"timestamp": SqlTimestamp,
+	}
+	for typname, want := range expected {
+		got, err := pgTypeToSqlDataType(typname, "t", "c")
+		if err != nil || got != want {
+			t.Errorf("%s got %v, %v; want %v", typname, got, err, want)
+		}
+	}
+
+	for _, typname := range []string{"tsvector", "json", "jsonb"} {
+		if _, err := pgTypeToSqlDataType(typname, "t", "c"); err != ErrSkipColumn {
+			t.Errorf("%s got %v; want ErrSkipColumn", typname, err)
+		}
+	}
+	_, err := pgTypeToSqlDataType("timestamptz", "t", "c")
+	if v, ok := err.(NoSuchDataTypeError); !ok || v.SqlTypeName != "timestamptz" || v.TableName != "t" || v.ColumnName != "c" {
+		t.Errorf("timestamptz got %v; want NoSuchDataTypeError", err)
+	}
+}
+
+func TestNumericTypmod(t *testing.T) {
+	//numeric(12,2) is stored as ((12 << 16) | 2) + 4
+	if p, s := numericTypmod((12<<16 | 2) + 4); p != 12 || s != 2 {
+		t.Errorf("got numeric(%d,%d); want numeric(12,2)", p, s)
+	}
+	if p, s := numericTypmod(-1); p != 0 || s != 0 {
+		t.Errorf("got numeric(%d,%d) for an unconstrained numeric", p, s)
+	}
+}
+
+func TestSingleColumnConstraints(t *testing.T) {
+	constraints := []string{"a_key", "b_key", "b_key", "c_key"}
+	columns := []string{"id", "make", "model", "vin"}
+	got := singleColumnConstraints(constraints, columns)
+	if !reflect.DeepEqual(got, []string{"id", "vin"}) {
+		t.Errorf("got %v; want [id vin]", got)
+	}
+	if got := singleColumnConstraints(nil, nil); len(got) != 0 {
+		t.Errorf("got %v; want none", got)
+	}
+}
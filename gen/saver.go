@@ -0,0 +1,36 @@
+package sillyquill_gen
+
+import "io"
+import "text/template"
+
+type ColumnSaver struct {
+	TheColumnType       *ColumnType
+	TheColumnizedStruct *ColumnizedStruct
+	Template            *template.Template
+}
+
+func NewColumnSaverFor(s *ColumnizedStruct,
+	columnInterfaces *ColumnType) *ColumnSaver {
+	this := new(ColumnSaver)
+	this.TheColumnType = columnInterfaces
+	this.TheColumnizedStruct = s
+
+	return this
+}
+
+func (this *ColumnSaver) Imports() []string {
+	return []string{"github.com/hydrogen18/sillyquill/rt",
+		"context",
+		"database/sql",
+		"bytes",
+		"fmt",
+	}
+}
+
+func (this *ColumnSaver) Suffix() string {
+	return "_saver"
+}
+
+func (this *ColumnSaver) Emit(w io.Writer) error {
+	return this.Template.Execute(w, this)
+}
@@ -0,0 +1,228 @@
+package sillyquill_gen
+
+import "encoding/json"
+import "fmt"
+import "os"
+import "sort"
+
+// Snapshot is the JSON representation of an introspected schema. It
+// is written by the snapshot command and read by SnapshotAdapter so
+// that generation can be repeated without a database.
+type Snapshot struct {
+	Schema string           `json:"schema"`
+	Tables []*SnapshotTable `json:"tables"`
+}
+
+type SnapshotTable struct {
+	TableName         string            `json:"name"`
+	TableColumns      []*SnapshotColumn `json:"columns"`
+	PrimaryKeyColumns []string          `json:"primary_key,omitempty"`
+	UniqueColumns     []string          `json:"unique,omitempty"`
+	ForeignKeyColumns []string          `json:"foreign_keys,omitempty"`
+}
+
+type SnapshotColumn struct {
+	ColumnName string      `json:"name"`
+	SqlType    SqlDataType `json:"type"`
+	IsNullable bool        `json:"nullable"`
+	Labels     []string    `json:"enum_labels,omitempty"`
+	Remark     string      `json:"comment,omitempty"`
+	Precision  int         `json:"precision,omitempty"`
+	Scale      int         `json:"scale,omitempty"`
+}
+
+var sqlDataTypeNames = map[SqlDataType]string{
+	SqlInt:       "integer",
+	SqlBigInt:    "bigint",
+	SqlByteArray: "bytea",
+	SqlVarChar:   "varchar",
+	SqlBoolean:   "boolean",
+	SqlTimestamp: "timestamp",
+	SqlFloat64:   "double precision",
+	SqlText:      "text",
+	SqlNumeric:   "numeric",
+	SqlDate:      "date",
+	SqlSmallInt:  "smallint",
+	SqlReal:      "real",
+}
+
+func (this SqlDataType) MarshalText() ([]byte, error) {
+	name, ok := sqlDataTypeNames[this]
+	if !ok {
+		return nil, fmt.Errorf("No name for data type %d", int(this))
+	}
+	return []byte(name), nil
+}
+
+func (this *SqlDataType) UnmarshalText(text []byte) error {
+	for dt, name := range sqlDataTypeNames {
+		if name == string(text) {
+			*this = dt
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown data type %q", string(text))
+}
+
+func (this *SnapshotColumn) IsCreationTimestamp() bool {
+	return this.Name() == "created_at" && this.DataType() == SqlTimestamp
+}
+
+func (this *SnapshotColumn) IsUpdateTimestamp() bool {
+	return this.Name() == "updated_at" && this.DataType() == SqlTimestamp
+}
+
+func (this *SnapshotColumn) Name() string {
+	return this.ColumnName
+}
+
+func (this *SnapshotColumn) DataType() SqlDataType {
+	return this.SqlType
+}
+
+func (this *SnapshotColumn) Nullable() bool {
+	return this.IsNullable
+}
+
+func (this *SnapshotColumn) Comment() string {
+	return this.Remark
+}
+
+func (this *SnapshotColumn) EnumLabels() []string {
+	return this.Labels
+}
+
+func (this *SnapshotColumn) NumericPrecision() (int, int) {
+	return this.Precision, this.Scale
+}
+
+func (this *SnapshotTable) Name() string {
+	return this.TableName
+}
+
+func (this *SnapshotTable) Columns() ([]Column, error) {
+	result := make([]Column, len(this.TableColumns))
+	for i, v := range this.TableColumns {
+		result[i] = v
+	}
+	return result, nil
+}
+
+func (this *SnapshotTable) PrimaryKey() ([]string, error) {
+	return this.PrimaryKeyColumns, nil
+}
+
+func (this *SnapshotTable) Unique() ([]string, error) {
+	return this.UniqueColumns, nil
+}
+
+func (this *SnapshotTable) ForeignKeys() ([]string, error) {
+	return this.ForeignKeyColumns, nil
+}
+
+// NewSnapshotTable queries a table for its columns and constraints
+// and returns the result as a SnapshotTable
+func NewSnapshotTable(t Table) (*SnapshotTable, error) {
+	st := new(SnapshotTable)
+	st.TableName = t.Name()
+
+	columns, err := t.Columns()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range columns {
+		sc := &SnapshotColumn{
+			ColumnName: c.Name(),
+			SqlType:    c.DataType(),
+			IsNullable: c.Nullable(),
+		}
+		if v, ok := c.(interface {
+			EnumLabels() []string
+		}); ok {
+			sc.Labels = v.EnumLabels()
+		}
+		if v, ok := c.(numericColumn); ok {
+			sc.Precision, sc.Scale = v.NumericPrecision()
+		}
+		if v, ok := c.(interface {
+			Comment() string
+		}); ok {
+			sc.Remark = v.Comment()
+		}
+		st.TableColumns = append(st.TableColumns, sc)
+	}
+
+	st.PrimaryKeyColumns, err = t.PrimaryKey()
+	if err != nil {
+		return nil, err
+	}
+	st.UniqueColumns, err = t.Unique()
+	if err != nil {
+		return nil, err
+	}
+	st.ForeignKeyColumns, err = t.ForeignKeys()
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// NewSnapshot queries every table for its columns and constraints
+// and returns the result as a Snapshot
+func NewSnapshot(schema string, tables []Table) (*Snapshot, error) {
+	this := new(Snapshot)
+	this.Schema = schema
+	for _, t := range tables {
+		st, err := NewSnapshotTable(t)
+		if err != nil {
+			return nil, err
+		}
+		this.Tables = append(this.Tables, st)
+	}
+	sort.Slice(this.Tables, func(i, j int) bool {
+		return this.Tables[i].TableName < this.Tables[j].TableName
+	})
+
+	return this, nil
+}
+
+// WriteFile writes the snapshot as indented JSON to the named file,
+// or to standard output if filename is empty
+func (this *Snapshot) WriteFile(filename string) error {
+	data, err := json.MarshalIndent(this, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if filename == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// SnapshotAdapter reads the tables from a file written by the
+// snapshot command
+type SnapshotAdapter struct {
+	File string
+}
+
+func (this *SnapshotAdapter) Tables() ([]Table, error) {
+	data, err := os.ReadFile(this.File)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	err = json.Unmarshal(data, &snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing snapshot %q:%v", this.File, err)
+	}
+
+	var results []Table
+	for _, t := range snapshot.Tables {
+		results = append(results, t)
+	}
+	return results, nil
+}
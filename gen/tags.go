@@ -0,0 +1,130 @@
+package sillyquill_gen
+
+import "fmt"
+import "sort"
+import "strings"
+import "unicode"
+
+// The styles used to derive the name in a struct tag from a column
+const TagStyleSnake = "snake"
+const TagStyleCamel = "camel"
+const TagStyleColumn = "column"
+
+// StructTags configures the tags on the fields of generated models
+type StructTags struct {
+	//Styles maps each tag key, such as "json" or "db", to the
+	//style its names are derived in. A style may be followed by
+	//options for the tag such as "snake,omitempty".
+	Styles map[string]string
+	//Overrides maps a table name and then a column name to tag
+	//values that replace the derived ones. For example a value
+	//of "-" for the key "json" gives the field the tag json:"-"
+	Overrides map[string]map[string]map[string]string
+}
+
+// CamelCaseToUnderscores lowercases v, separating words that start
+// with an uppercase letter with an underscore
+func CamelCaseToUnderscores(v string) string {
+	var output []rune
+	var last rune
+	for _, c := range v {
+		if unicode.IsUpper(c) {
+			if unicode.IsLower(last) || unicode.IsDigit(last) {
+				output = append(output, '_')
+			}
+			c = unicode.ToLower(c)
+		}
+		output = append(output, c)
+		last = c
+	}
+	return string(output)
+}
+
+func tagName(style, columnName string) (string, error) {
+	switch style {
+	case TagStyleSnake:
+		return CamelCaseToUnderscores(columnName), nil
+	case TagStyleCamel:
+		return privatizeTypeName(UnderscoresToCamelCase(columnName)), nil
+	case TagStyleColumn:
+		return columnName, nil
+	}
+	return "", fmt.Errorf("Unknown struct tag style %q", style)
+}
+
+func sortedKeys(maps ...map[string]string) []string {
+	keys := make(map[string]int)
+	for _, m := range maps {
+		for key, _ := range m {
+			keys[key] = 0
+		}
+	}
+	var result []string
+	for key, _ := range keys {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (this StructTags) value(key, tableName, columnName string) (string, bool, error) {
+	value, ok := this.Overrides[tableName][columnName][key]
+	if ok {
+		return value, true, nil
+	}
+	style, ok := this.Styles[key]
+	if !ok {
+		return "", false, nil
+	}
+	var options string
+	if i := strings.Index(style, ","); i >= 0 {
+		style, options = style[:i], style[i:]
+	}
+	name, err := tagName(style, columnName)
+	if err != nil {
+		return "", false, err
+	}
+	return name + options, true, nil
+}
+
+// Name returns the name the tag key gives the field of a column, which
+// is fieldName when there is no such tag or it does not set a name. The
+// name is "-" when the field is excluded.
+func (this StructTags) Name(key, tableName, columnName, fieldName string) (string, error) {
+	value, _, err := this.value(key, tableName, columnName)
+	if err != nil {
+		return "", err
+	}
+	if i := strings.Index(value, ","); i >= 0 {
+		value = value[:i]
+	}
+	if value == "" {
+		return fieldName, nil
+	}
+	return value, nil
+}
+
+// Tag returns the tag for the field of a column, or the empty string
+// when no tags are configured
+func (this StructTags) Tag(tableName, columnName string) (string, error) {
+	var tags []string
+	for _, key := range sortedKeys(this.Styles, this.Overrides[tableName][columnName]) {
+		value, _, err := this.value(key, tableName, columnName)
+		if err != nil {
+			return "", err
+		}
+		tags = append(tags, fmt.Sprintf("%s:%q", key, value))
+	}
+	return strings.Join(tags, " "), nil
+}
+
+// ExcludedTag returns the tag for fields that are never serialized.
+// The json key is always present since encoding/json serializes
+// untagged fields.
+func (this StructTags) ExcludedTag() string {
+	var tags []string
+	for _, key := range sortedKeys(this.Styles, map[string]string{"json": ""}) {
+		tags = append(tags, fmt.Sprintf("%s:\"-\"", key))
+	}
+	return strings.Join(tags, " ")
+}
@@ -0,0 +1,80 @@
+package sillyquill_gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStructTags(t *testing.T) {
+	tags := StructTags{
+		Styles: map[string]string{
+			"json": "camel,omitempty",
+			"db":   "column",
+			"yaml": "snake",
+		},
+		Overrides: map[string]map[string]map[string]string{
+			"users": {
+				"password_hash": {"json": "-"},
+				"Email":         {"xml": "email"},
+			},
+		},
+	}
+
+	for _, test := range []struct {
+		table, column, expected string
+	}{
+		{"users", "created_at", `db:"created_at" json:"createdAt,omitempty" yaml:"created_at"`},
+		{"users", "password_hash", `db:"password_hash" json:"-" yaml:"password_hash"`},
+		{"users", "Email", `db:"Email" json:"email,omitempty" xml:"email" yaml:"email"`},
+		{"users", "lastLoginAt", `db:"lastLoginAt" json:"lastLoginAt,omitempty" yaml:"last_login_at"`},
+		{"cars", "password_hash", `db:"password_hash" json:"passwordHash,omitempty" yaml:"password_hash"`},
+	} {
+		actual, err := tags.Tag(test.table, test.column)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != test.expected {
+			t.Errorf("%s.%s: got %s; want %s", test.table, test.column, actual, test.expected)
+		}
+	}
+
+	if v := tags.ExcludedTag(); v != `db:"-" json:"-" yaml:"-"` {
+		t.Errorf("got excluded tag %s", v)
+	}
+	if v := (StructTags{}).ExcludedTag(); v != `json:"-"` {
+		t.Errorf("got excluded tag %s without styles", v)
+	}
+	if v, _ := (StructTags{}).Tag("users", "id"); v != "" {
+		t.Errorf("got tag %s without styles", v)
+	}
+
+	tags.Styles["json"] = "kebab"
+	if _, err := tags.Tag("users", "id"); err == nil {
+		t.Errorf("unknown style accepted")
+	}
+}
+
+func TestModelStructTags(t *testing.T) {
+	me := NewModelEmitter()
+	me.Package = "dal"
+	me.StructTags = StructTags{
+		Styles: map[string]string{"json": "snake"},
+		Overrides: map[string]map[string]map[string]string{
+			"cars": {"make": {"json": "-"}},
+		},
+	}
+	files, err := me.Render(carsTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(files["cars.go"])
+	for _, expected := range []string{
+		"Id   int32  `json:\"id\"`",
+		"Make string `json:\"-\"`",
+		"} `json:\"-\"`",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("%s not in\n%s", expected, src)
+		}
+	}
+}
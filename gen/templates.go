@@ -0,0 +1,142 @@
+package sillyquill_gen
+
+import "embed"
+import "fmt"
+import "io"
+import "os"
+import "path/filepath"
+import "sort"
+import "strings"
+import "text/template"
+
+//go:embed templates/*.tmpl
+var builtinTemplateFiles embed.FS
+
+const templateExt = ".tmpl"
+
+// The names of the templates used by the builtin emitters
+const ModelTemplateName = "model"
+const ColumnsTemplateName = "columns"
+const LoaderTemplateName = "loader"
+const SaverTemplateName = "saver"
+
+// The name of the template rendered by the json emitter
+const JSONTemplateName = "json"
+
+var builtinTemplateNames = []string{
+	ModelTemplateName,
+	ColumnsTemplateName,
+	LoaderTemplateName,
+	SaverTemplateName,
+	JSONTemplateName,
+}
+
+var templateFuncs = template.FuncMap{
+	"camelCase": UnderscoresToCamelCase,
+	"privatize": privatizeTypeName,
+	"join":      strings.Join,
+}
+
+// Templates holds the parsed templates used to emit code, keyed by
+// the name of the template file without the extension
+type Templates struct {
+	byName map[string]*template.Template
+}
+
+func parseTemplate(name string, text []byte) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(string(text))
+}
+
+// LoadTemplates parses the builtin templates and then every *.tmpl
+// file in dir, if dir is not empty. A file in dir with the name of a
+// builtin template replaces it, any other file is an additional
+// template that is rendered to its own file for each table.
+func LoadTemplates(dir string) (*Templates, error) {
+	this := &Templates{
+		byName: make(map[string]*template.Template),
+	}
+
+	for _, name := range builtinTemplateNames {
+		text, err := builtinTemplateFiles.ReadFile("templates/" + name + templateExt)
+		if err != nil {
+			return nil, err
+		}
+		this.byName[name], err = parseTemplate(name, text)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dir == "" {
+		return this, nil
+	}
+
+	filenames, err := filepath.Glob(filepath.Join(dir, "*"+templateExt))
+	if err != nil {
+		return nil, err
+	}
+	for _, filename := range filenames {
+		text, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(filename), templateExt)
+		this.byName[name], err = parseTemplate(name, text)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing template %q:%v", filename, err)
+		}
+	}
+
+	return this, nil
+}
+
+var builtinTemplates = func() *Templates {
+	this, err := LoadTemplates("")
+	if err != nil {
+		panic(err)
+	}
+	return this
+}()
+
+func (this *Templates) Lookup(name string) *template.Template {
+	return this.byName[name]
+}
+
+// Additional returns the names of the templates that are not builtin
+// in sorted order
+func (this *Templates) Additional() []string {
+	var result []string
+	for name, _ := range this.byName {
+		builtin := false
+		for _, v := range builtinTemplateNames {
+			if v == name {
+				builtin = true
+			}
+		}
+		if !builtin {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// TemplateEmitter emits the code of an additional template. The
+// template receives the *ColumnizedStruct of the table.
+type TemplateEmitter struct {
+	Template *template.Template
+	Data     *ColumnizedStruct
+}
+
+func (this *TemplateEmitter) Imports() []string {
+	//Unused imports are removed after rendering
+	return this.Data.Imports()
+}
+
+func (this *TemplateEmitter) Suffix() string {
+	return "_" + this.Template.Name()
+}
+
+func (this *TemplateEmitter) Emit(w io.Writer) error {
+	return this.Template.Execute(w, this.Data)
+}
@@ -0,0 +1,56 @@
+package sillyquill_gen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate := func(name, text string) {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTemplate("saver.tmpl", "func (this *{{.TheColumnizedStruct.SingularModelName}}) Saved() bool {\n\treturn true\n}\n")
+	writeTemplate("names.tmpl", "{{range .Fields}}const {{privatize $.SingularModelName}}{{.Name}} = {{printf \"%q\" .Name}}\n{{end}}")
+	writeTemplate("README", "not a template")
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(templates.Additional(), []string{"names"}) {
+		t.Errorf("got additional templates %v", templates.Additional())
+	}
+
+	me := NewModelEmitter()
+	me.Package = "dal"
+	me.Templates = templates
+	files, err := me.Render(carsTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saver := string(files["cars_saver.go"])
+	if !strings.Contains(saver, "func (this *Car) Saved() bool") || strings.Contains(saver, "Save(") {
+		t.Errorf("builtin saver template not replaced:\n%s", saver)
+	}
+	names := string(files["cars_names.go"])
+	if !strings.Contains(names, `const carMake = "Make"`) {
+		t.Errorf("additional template not rendered:\n%s", names)
+	}
+	if _, ok := files["cars_loader.go"]; !ok {
+		t.Errorf("builtin loader template not rendered")
+	}
+
+	writeTemplate("broken.tmpl", "{{.Fields")
+	_, err = LoadTemplates(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.tmpl") {
+		t.Errorf("got %v; want parse error naming broken.tmpl", err)
+	}
+}
@@ -8,6 +8,9 @@ import "github.com/hydrogen18/sillyquill/rt"
 import _ "github.com/lib/pq"
 import "os"
 import "time"
+import "context"
+import "encoding/json"
+import "fmt"
 
 type TestSuite struct {
 	db *sql.DB
@@ -172,7 +175,8 @@ func (s *TestSuite) TestNumericNull(c *C) {
 	aNumber := new(dal.NullNumber)
 	var v sillyquill_rt.NullNumeric
 	aNumber.SetTitle("kitties")
-	v.SetString("135135.16136")
+	v.Numeric.SetString("135135.16136")
+	v.Valid = true
 	aNumber.SetValue(&v)
 
 	err = aNumber.Create(s.db)
@@ -192,6 +196,24 @@ func (s *TestSuite) TestNumericNull(c *C) {
 	c.Assert(sameNumber.Value, IsNil)
 }
 
+func (s *TestSuite) TestNumericPrecision(c *C) {
+	aPrice := new(dal.Price)
+	var v sillyquill_rt.Numeric
+	v.SetString("19.995")
+	aPrice.SetAmount(v)
+
+	err := aPrice.Create(s.db)
+	c.Assert(err, IsNil)
+	c.Assert(aPrice.Amount.String(), Equals, "20.00")
+
+	var discount sillyquill_rt.NullNumeric
+	discount.Numeric.SetString("12.5")
+	discount.Valid = true
+	aPrice.SetDiscount(&discount)
+	err = aPrice.Save(s.db)
+	c.Assert(err, FitsTypeOf, sillyquill_rt.NumericOverflowError{})
+}
+
 func (s *TestSuite) TestArchiveFiles(c *C) {
 	aFile := new(dal.ArchiveFile)
 	aFile.SetName("foo.txt")
@@ -345,3 +367,44 @@ func (s *TestSuite) TestCreateTruck(c *C) {
 	sameTruck.IsSet = aTruck.IsSet //Clear flags
 	c.Assert(*sameTruck, Equals, *aTruck)
 }
+
+func (s *TestSuite) TestAudit(c *C) {
+	ctx := sillyquill_rt.WithActor(context.Background(), "tester")
+	invoice := new(dal.Invoice)
+	invoice.SetCode(fmt.Sprintf("INV-%d", time.Now().UnixNano()))
+	invoice.SetCustomer("bob")
+	err := invoice.CreateContext(ctx, s.db)
+	c.Assert(err, IsNil)
+
+	invoice.SetCustomer("alice")
+	err = invoice.SaveContext(ctx, s.db)
+	c.Assert(err, IsNil)
+
+	var tableName, primaryKey, operation, actor, diff string
+	err = s.db.QueryRow("SELECT table_name,primary_key,operation,actor,diff FROM audit_log ORDER BY id DESC LIMIT 1").Scan(
+		&tableName, &primaryKey, &operation, &actor, &diff)
+	c.Assert(err, IsNil)
+	c.Check(tableName, Equals, "invoices")
+	c.Check(operation, Equals, sillyquill_rt.AuditUpdate)
+	c.Check(actor, Equals, "tester")
+
+	//The primary key is recorded, not the UNIQUE id column
+	var key map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(primaryKey), &key), IsNil)
+	c.Check(key, DeepEquals, map[string]interface{}{"code": invoice.Code})
+
+	var changes map[string]sillyquill_rt.AuditChange
+	c.Assert(json.Unmarshal([]byte(diff), &changes), IsNil)
+	c.Check(changes, DeepEquals, map[string]sillyquill_rt.AuditChange{
+		"customer": {Old: "bob", New: "alice"},
+	})
+
+	//The primary key and the diff are stored as jsonb
+	var code, oldCustomer, newCustomer string
+	err = s.db.QueryRow("SELECT primary_key->>'code',diff->'customer'->>'old',diff->'customer'->>'new' FROM audit_log ORDER BY id DESC LIMIT 1").Scan(
+		&code, &oldCustomer, &newCustomer)
+	c.Assert(err, IsNil)
+	c.Check(code, Equals, invoice.Code)
+	c.Check(oldCustomer, Equals, "bob")
+	c.Check(newCustomer, Equals, "alice")
+}
@@ -0,0 +1,160 @@
+package gen_test
+
+import . "gopkg.in/check.v1"
+import "github.com/hydrogen18/sillyquill/gen_test/dal"
+import "github.com/hydrogen18/sillyquill/rt"
+import "time"
+
+// ModelSuite tests the generated code that does not need a database
+type ModelSuite struct{}
+
+var _ = Suite(&ModelSuite{})
+
+func (s *ModelSuite) TestDirtyTracking(c *C) {
+	car := new(dal.Car)
+	car.Make = "chevy"
+	dal.Cars.Make.SetLoaded(car, true)
+	c.Check(car.HasChanges(), Equals, false)
+
+	car.SetMake("chevy")
+	c.Check(car.HasChanges(), Equals, false)
+
+	car.SetMake("ford")
+	c.Check(car.HasChanges(), Equals, true)
+	c.Check(car.Changes(), DeepEquals, map[dal.CarColumn][2]interface{}{
+		dal.Cars.Make: {"chevy", "ford"},
+	})
+
+	car.Revert()
+	c.Check(car.Make, Equals, "chevy")
+	c.Check(car.HasChanges(), Equals, false)
+}
+
+func (s *ModelSuite) TestDirtyTrackingNumericInPlace(c *C) {
+	aNumber := new(dal.Number)
+	aNumber.Value.SetString("1.50")
+	dal.Numbers.Value.SetLoaded(aNumber, true)
+
+	aNumber.Value.SetString("2.50")
+	aNumber.SetValue(aNumber.Value)
+	c.Check(aNumber.HasChanges(), Equals, true)
+	original := aNumber.Changes()[dal.Numbers.Value][0].(sillyquill_rt.Numeric)
+	c.Check(original.String(), Equals, "1.50")
+
+	aNumber.Revert()
+	c.Check(aNumber.Value.String(), Equals, "1.50")
+	aNumber.Value.SetString("3.50")
+	aNumber.SetValue(aNumber.Value)
+	c.Check(aNumber.HasChanges(), Equals, true)
+}
+
+func (s *ModelSuite) TestDirtyTrackingBytesInPlace(c *C) {
+	aFile := new(dal.ArchiveFile)
+	aFile.Data = []byte{0x1, 0x2, 0x3}
+	dal.ArchiveFiles.Data.SetLoaded(aFile, true)
+
+	aFile.Data[0] = 0x9
+	aFile.SetData(aFile.Data)
+	c.Check(aFile.HasChanges(), Equals, true)
+
+	aFile.Revert()
+	c.Check(aFile.Data, DeepEquals, []byte{0x1, 0x2, 0x3})
+}
+
+func (s *ModelSuite) TestDirtyTrackingNull(c *C) {
+	aNumber := new(dal.NullNumber)
+	dal.NullNumbers.Value.SetLoaded(aNumber, true)
+	aNumber.SetValue(nil)
+	c.Check(aNumber.HasChanges(), Equals, false)
+	aNumber.SetValue(&sillyquill_rt.NullNumeric{})
+	c.Check(aNumber.HasChanges(), Equals, false)
+
+	var v sillyquill_rt.NullNumeric
+	v.Numeric.SetString("1.50")
+	v.Valid = true
+	aNumber.SetValue(&v)
+	c.Check(aNumber.HasChanges(), Equals, true)
+	c.Check(aNumber.Changes()[dal.NullNumbers.Value][0], IsNil)
+
+	aNumber.Revert()
+	c.Check(aNumber.Value, IsNil)
+	dal.NullNumbers.Value.SetLoaded(aNumber, false)
+	aNumber.SetValue(&v)
+	dal.NullNumbers.Value.SetLoaded(aNumber, true)
+	aNumber.SetValue(nil)
+	c.Check(aNumber.HasChanges(), Equals, true)
+	original := aNumber.Changes()[dal.NullNumbers.Value][0].(sillyquill_rt.Numeric)
+	c.Check(original.String(), Equals, "1.50")
+}
+
+func (s *ModelSuite) TestLoadedModelsAreComparable(c *C) {
+	aTruck := new(dal.Truck)
+	aTruck.Id = 1
+	aTruck.Make = "volvo"
+	aTruck.Model = "fh16"
+	aTruck.CreatedAt = time.Now()
+	for _, v := range dal.TrucksColumns {
+		v.SetLoaded(aTruck, true)
+	}
+
+	sameTruck := *aTruck
+	c.Check(sameTruck == *aTruck, Equals, true)
+	c.Check(sameTruck, Equals, *aTruck)
+
+	sameTruck.SetMake("scania")
+	c.Check(sameTruck == *aTruck, Equals, false)
+	sameTruck.Revert()
+	c.Check(sameTruck, Equals, *aTruck)
+}
+
+func (s *ModelSuite) TestAuditRequiresPrimaryKey(c *C) {
+	invoice := new(dal.Invoice)
+	invoice.SetId(1)
+	invoice.SetCustomer("bob")
+	err := invoice.Save(nil)
+	c.Check(err, FitsTypeOf, sillyquill_rt.RowNotUniquelyIdentifiableError{})
+	err = invoice.Delete(nil)
+	c.Check(err, FitsTypeOf, sillyquill_rt.RowNotUniquelyIdentifiableError{})
+}
+
+func (s *ModelSuite) TestDecodeCursor(c *C) {
+	secret := []byte("not so secret")
+	cursor, err := sillyquill_rt.EncodeCursor(secret, "cars", "chevy", "silverado")
+	c.Assert(err, IsNil)
+
+	car, err := dal.DecodeCarCursor(secret, cursor)
+	c.Assert(err, IsNil)
+	c.Check(car.Make, Equals, "chevy")
+	c.Check(car.Model, Equals, "silverado")
+
+	_, err = dal.DecodeCarCursor([]byte("another secret"), cursor)
+	c.Check(err, FitsTypeOf, sillyquill_rt.InvalidCursorError{})
+	_, err = dal.DecodeTruckCursor(secret, cursor)
+	c.Check(err, FitsTypeOf, sillyquill_rt.InvalidCursorError{})
+}
+
+func (s *ModelSuite) TestValidate(c *C) {
+	aPrice := new(dal.Price)
+	var v sillyquill_rt.Numeric
+	v.SetString("19.995")
+	aPrice.SetAmount(v)
+	c.Assert(aPrice.Validate(), IsNil)
+	c.Check(aPrice.Amount.String(), Equals, "20.00")
+	c.Check(v.String(), Equals, "19.995")
+
+	aPrice.SetDiscount(nil)
+	c.Check(aPrice.Validate(), IsNil)
+
+	var discount sillyquill_rt.NullNumeric
+	discount.Numeric.SetString("12.5")
+	discount.Valid = true
+	aPrice.SetDiscount(&discount)
+	err := aPrice.Validate()
+	c.Check(err, DeepEquals, sillyquill_rt.NumericOverflowError{
+		Column:    "discount",
+		Value:     "12.5",
+		Precision: 4,
+		Scale:     3,
+	})
+	c.Check(discount.Numeric.String(), Equals, "12.5")
+}
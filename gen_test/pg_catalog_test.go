@@ -0,0 +1,109 @@
+package gen_test
+
+import . "gopkg.in/check.v1"
+import "database/sql"
+import "github.com/hydrogen18/sillyquill/gen"
+import _ "github.com/lib/pq"
+import "os"
+
+// PgCatalogSuite runs the pg_catalog schema adapter against tables
+// created in their own schema, so that schema.sql stays readable by
+// every adapter
+type PgCatalogSuite struct {
+	db *sql.DB
+}
+
+var _ = Suite(&PgCatalogSuite{})
+
+const pgCatalogSchema = `drop schema if exists sillyquill_pg_catalog cascade;
+create schema sillyquill_pg_catalog;
+create type sillyquill_pg_catalog.mood as enum ('sad', 'ok', 'happy');
+create table sillyquill_pg_catalog.rooms (
+	id serial primary key,
+	code varchar not null unique,
+	floor int not null,
+	wing varchar not null,
+	mood sillyquill_pg_catalog.mood not null,
+	tags varchar[] null,
+	readings int[][] null,
+	moods sillyquill_pg_catalog.mood[] null,
+	stamps timestamptz[] null,
+	unique (floor, wing),
+	constraint rooms_floor_wing_excl exclude using btree (floor with =, lower(wing) with =)
+);
+create view sillyquill_pg_catalog.room_codes as
+	select id, code from sillyquill_pg_catalog.rooms;
+create materialized view sillyquill_pg_catalog.room_floors as
+	select distinct floor from sillyquill_pg_catalog.rooms;`
+
+func (s *PgCatalogSuite) SetUpSuite(c *C) {
+	var err error
+	s.db, err = sql.Open("postgres", os.Getenv("DB"))
+	c.Assert(err, IsNil)
+	_, err = s.db.Exec(pgCatalogSchema)
+	c.Assert(err, IsNil)
+}
+
+func (s *PgCatalogSuite) TearDownSuite(c *C) {
+	if s.db != nil {
+		s.db.Exec("drop schema if exists sillyquill_pg_catalog cascade")
+		s.db.Close()
+	}
+}
+
+func (s *PgCatalogSuite) TestTables(c *C) {
+	adapter := sillyquill_gen.NewPgCatalogAdapter(s.db, "sillyquill_pg_catalog")
+	tables, err := adapter.Tables()
+	c.Assert(err, IsNil)
+	//Views and materialized views get no models
+	c.Assert(len(tables), Equals, 1)
+	table := tables[0].(*sillyquill_gen.PgCatalogTable)
+	c.Check(table.Name(), Equals, "rooms")
+
+	columns, err := table.Columns()
+	c.Assert(err, IsNil)
+	var names []string
+	for _, column := range columns {
+		names = append(names, column.Name())
+	}
+	c.Check(names, DeepEquals, []string{"id", "code", "floor", "wing", "mood"})
+	c.Check(columns[4].(*sillyquill_gen.PgCatalogColumn).EnumLabels(), DeepEquals, []string{"sad", "ok", "happy"})
+
+	primaryKey, err := table.PrimaryKey()
+	c.Assert(err, IsNil)
+	c.Check(primaryKey, DeepEquals, []string{"id"})
+	unique, err := table.Unique()
+	c.Assert(err, IsNil)
+	c.Check(unique, DeepEquals, []string{"code"})
+
+	c.Check(table.ExclusionConstraints(), DeepEquals, []sillyquill_gen.PgCatalogConstraint{
+		{Name: "rooms_floor_wing_excl", Columns: []string{"floor"}},
+	})
+}
+
+func (s *PgCatalogSuite) TestArrayColumns(c *C) {
+	adapter := sillyquill_gen.NewPgCatalogAdapter(s.db, "sillyquill_pg_catalog")
+	tables, err := adapter.Tables()
+	c.Assert(err, IsNil)
+	c.Assert(len(tables), Equals, 1)
+	arrays := tables[0].(*sillyquill_gen.PgCatalogTable).ArrayColumns()
+	c.Assert(len(arrays), Equals, 4)
+
+	expected := []struct {
+		name     string
+		dataType sillyquill_gen.SqlDataType
+	}{
+		{"tags", sillyquill_gen.SqlVarChar},
+		{"readings", sillyquill_gen.SqlInt},
+		{"moods", sillyquill_gen.SqlText},
+		//timestamptz is not supported
+		{"stamps", sillyquill_gen.SqlDataType(0)},
+	}
+	for i, v := range expected {
+		c.Check(arrays[i].Name(), Equals, v.name)
+		c.Check(arrays[i].IsArray(), Equals, true)
+		c.Check(arrays[i].DataType(), Equals, v.dataType)
+		c.Check(arrays[i].Nullable(), Equals, true)
+	}
+	c.Check(arrays[2].EnumLabels(), DeepEquals, []string{"sad", "ok", "happy"})
+}
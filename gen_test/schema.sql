@@ -57,8 +57,31 @@ create table null_numbers (
 	title varchar not null
 );
 
+create table prices (
+	id serial unique,
+	amount numeric(8,2) not null,
+	discount numeric(4,3) null
+);
+
 create table not_uniquely_identifiables (
 	id serial not null,
 	name varchar not null,
 	age int not null
+);
+
+create table invoices (
+	code varchar not null,
+	id serial unique,
+	customer varchar not null,
+	PRIMARY KEY(code)
+);
+
+create table audit_log (
+	id bigserial PRIMARY KEY,
+	table_name text NOT NULL,
+	primary_key jsonb NOT NULL,
+	operation text NOT NULL,
+	changed_at timestamp NOT NULL,
+	actor text,
+	diff jsonb NOT NULL
 );
\ No newline at end of file
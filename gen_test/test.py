@@ -51,6 +51,10 @@ if passed:
         fout.write('output-dir="')
         fout.write(output_dir)
         fout.write('"\n')
+        fout.write('[tables.invoices]\n')
+        fout.write('audit=true\n')
+        fout.write('[tables.audit_log]\n')
+        fout.write('exclude=true\n')
         exe = os.path.join(GOPATH,'bin','sillyquill')
         proc = subprocess.Popen([exe,'-conf',fout.name])
         retcode = proc.wait()
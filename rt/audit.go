@@ -0,0 +1,69 @@
+package sillyquill_rt
+
+import "context"
+import "encoding/json"
+import "fmt"
+import "time"
+
+// The operations recorded in the audit table
+const AuditInsert = "INSERT"
+const AuditUpdate = "UPDATE"
+const AuditDelete = "DELETE"
+
+type actorKey struct{}
+
+// WithActor returns a context that records actor as the one making
+// changes to audited tables
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor set by WithActor, or the empty string
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// AuditChange is the value of a column before and after a change
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditEntry is a row written to the audit table
+type AuditEntry struct {
+	Table      string
+	PrimaryKey map[string]interface{}
+	Operation  string
+	Actor      string
+	Diff       map[string]AuditChange
+}
+
+// WriteAudit inserts entry into auditTable, which must have the
+// columns table_name, primary_key, operation, changed_at, actor
+// and diff. The primary key and the diff are written as JSON, so
+// they can be jsonb columns.
+func WriteAudit(ctx context.Context, db Executor, auditTable string, entry AuditEntry) error {
+	primaryKey, err := json.Marshal(entry.PrimaryKey)
+	if err != nil {
+		return err
+	}
+	diff, err := json.Marshal(entry.Diff)
+	if err != nil {
+		return err
+	}
+	var actor interface{}
+	if entry.Actor != "" {
+		actor = entry.Actor
+	}
+	query := fmt.Sprintf("INSERT INTO %q (table_name,primary_key,operation,changed_at,actor,diff) VALUES ($1,$2,$3,$4,$5,$6)",
+		auditTable)
+	_, err = db.ExecContext(ctx, query,
+		entry.Table,
+		string(primaryKey),
+		entry.Operation,
+		time.Now().UTC(),
+		actor,
+		string(diff))
+	return err
+}
@@ -0,0 +1,53 @@
+package sillyquill_rt
+
+import "context"
+import "database/sql"
+import "strings"
+import "testing"
+import "time"
+
+// recordingExecutor records the statements passed to ExecContext
+type recordingExecutor struct {
+	Executor
+	query string
+	args  []interface{}
+}
+
+func (this *recordingExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	this.query = query
+	this.args = args
+	return nil, nil
+}
+
+func TestWriteAudit(t *testing.T) {
+	db := new(recordingExecutor)
+	ctx := WithActor(context.Background(), "tester")
+	err := WriteAudit(ctx, db, "history", AuditEntry{
+		Table:      "cars",
+		PrimaryKey: map[string]interface{}{"id": int32(7)},
+		Operation:  AuditUpdate,
+		Actor:      ActorFrom(ctx),
+		Diff:       map[string]AuditChange{"make": {Old: "Ford", New: "Fiat"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(db.query, `INSERT INTO "history" (table_name,primary_key,operation,changed_at,actor,diff) `) {
+		t.Errorf("got query %q", db.query)
+	}
+	if len(db.args) != 6 {
+		t.Fatalf("got %d args; want 6", len(db.args))
+	}
+	if db.args[0] != "cars" || db.args[1] != `{"id":7}` || db.args[2] != AuditUpdate || db.args[4] != "tester" ||
+		db.args[5] != `{"make":{"old":"Ford","new":"Fiat"}}` {
+		t.Errorf("got args %v", db.args)
+	}
+	if _, ok := db.args[3].(time.Time); !ok {
+		t.Errorf("got changed_at %v", db.args[3])
+	}
+
+	err = WriteAudit(context.Background(), db, "history", AuditEntry{Table: "cars"})
+	if err != nil || db.args[4] != nil {
+		t.Errorf("got actor %v, %v; want nil", db.args[4], err)
+	}
+}
@@ -0,0 +1,90 @@
+package sillyquill_rt
+
+import "crypto/hmac"
+import "crypto/sha256"
+import "encoding/base64"
+import "encoding/json"
+import "fmt"
+import "strings"
+
+type InvalidCursorError struct {
+	Token  string
+	Reason string
+}
+
+func (this InvalidCursorError) Error() string {
+	return fmt.Sprintf("Invalid cursor %q:%s", this.Token, this.Reason)
+}
+
+// cursorSignature returns the HMAC-SHA256 of the encoded key of a
+// cursor. The table is signed but not part of the token, so a cursor
+// of one table is not valid for another.
+func cursorSignature(secret []byte, table string, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(table))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// EncodeCursor encodes the key of the last row of a page of table as
+// a token that is safe to use in URLs. The token is signed with
+// secret so that DecodeCursor rejects a token that was not created
+// with the same secret. The values of the key are not encrypted.
+func EncodeCursor(secret []byte, table string, key ...interface{}) (string, error) {
+	if len(secret) == 0 {
+		return "", fmt.Errorf("A secret is required to sign a cursor")
+	}
+	var values []json.RawMessage
+	for _, v := range key {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, data)
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	signature := base64.RawURLEncoding.EncodeToString(cursorSignature(secret, table, payload))
+	return payload + "." + signature, nil
+}
+
+// DecodeCursor decodes a token created by EncodeCursor for table with
+// the same secret, storing each value of the key in the matching
+// pointer
+func DecodeCursor(secret []byte, table string, token string, pointers ...interface{}) error {
+	if len(secret) == 0 {
+		return fmt.Errorf("A secret is required to check a cursor")
+	}
+	dot := strings.IndexByte(token, '.')
+	if dot < 0 {
+		return InvalidCursorError{Token: token, Reason: "cursor is not signed"}
+	}
+	payload := token[:dot]
+	signature, err := base64.RawURLEncoding.DecodeString(token[dot+1:])
+	if err != nil || !hmac.Equal(signature, cursorSignature(secret, table, payload)) {
+		return InvalidCursorError{Token: token, Reason: "signature does not match"}
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return InvalidCursorError{Token: token, Reason: err.Error()}
+	}
+	var values []json.RawMessage
+	err = json.Unmarshal(data, &values)
+	if err != nil {
+		return InvalidCursorError{Token: token, Reason: err.Error()}
+	}
+	if len(values) != len(pointers) {
+		return InvalidCursorError{Token: token, Reason: fmt.Sprintf("cursor has %d values, expected %d", len(values), len(pointers))}
+	}
+	for i, v := range values {
+		err = json.Unmarshal(v, pointers[i])
+		if err != nil {
+			return InvalidCursorError{Token: token, Reason: err.Error()}
+		}
+	}
+	return nil
+}
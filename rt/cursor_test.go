@@ -0,0 +1,76 @@
+package sillyquill_rt
+
+import "encoding/base64"
+import "strings"
+import "testing"
+import "time"
+
+var cursorSecret = []byte("not so secret")
+
+func TestCursorRoundTrip(t *testing.T) {
+	when := time.Date(2020, 5, 1, 12, 30, 0, 0, time.UTC)
+	token, err := EncodeCursor(cursorSecret, "cars", int64(42), "silverado", when)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(token, "cars") {
+		t.Errorf("cursor %q holds the table name", token)
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(token[:strings.IndexByte(token, '.')])
+	if strings.Contains(string(payload), "cars") {
+		t.Errorf("cursor %s holds the table name", payload)
+	}
+
+	var id int64
+	var model string
+	var at time.Time
+	err = DecodeCursor(cursorSecret, "cars", token, &id, &model, &at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 || model != "silverado" || !at.Equal(when) {
+		t.Errorf("got %d, %q, %v", id, model, at)
+	}
+}
+
+func TestCursorTampered(t *testing.T) {
+	token, err := EncodeCursor(cursorSecret, "cars", int64(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dot := strings.IndexByte(token, '.')
+	forged := base64.RawURLEncoding.EncodeToString([]byte("[43]")) + token[dot:]
+
+	var id int64
+	for i, test := range []struct {
+		secret []byte
+		table  string
+		token  string
+	}{
+		{cursorSecret, "cars", forged},
+		{cursorSecret, "cars", token[:dot]},
+		{cursorSecret, "cars", token[:len(token)-2]},
+		{cursorSecret, "cars", token + "A"},
+		{cursorSecret, "trucks", token},
+		{[]byte("another secret"), "cars", token},
+	} {
+		err := DecodeCursor(test.secret, test.table, test.token, &id)
+		if _, ok := err.(InvalidCursorError); !ok {
+			t.Errorf("#%d got %v; want InvalidCursorError", i, err)
+		}
+	}
+	if id != 0 {
+		t.Errorf("got %d from an invalid cursor", id)
+	}
+
+	var model string
+	if err := DecodeCursor(cursorSecret, "cars", token, &id, &model); err == nil {
+		t.Errorf("decoded a cursor with too few values")
+	}
+	if _, err := EncodeCursor(nil, "cars", int64(42)); err == nil {
+		t.Errorf("encoded a cursor without a secret")
+	}
+	if err := DecodeCursor(nil, "cars", token, &id); err == nil {
+		t.Errorf("decoded a cursor without a secret")
+	}
+}
@@ -1,5 +1,8 @@
 package sillyquill_rt
 
+import "context"
+import "database/sql"
+
 type Scanner interface {
 	Scan(...interface{}) error
 }
@@ -11,3 +14,36 @@ type Rows interface {
 	Err() error
 	Close() error
 }
+
+// Executor runs queries for the generated code. It is implemented by
+// *sql.DB, *sql.Tx and *sql.Conn
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// InTx calls f with a transaction. When db can begin a transaction,
+// such as a *sql.DB, one is begun and committed if f succeeds.
+// Otherwise db is assumed to be a transaction already and f is
+// called with it.
+func InTx(ctx context.Context, db Executor, f func(Executor) error) error {
+	beginner, ok := db.(txBeginner)
+	if !ok {
+		return f(db)
+	}
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	err = f(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
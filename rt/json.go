@@ -0,0 +1,80 @@
+package sillyquill_rt
+
+import "bytes"
+import "encoding/json"
+import "fmt"
+import "github.com/hydrogen18/sillyquill/dec"
+
+// JSONField is a member of the object written by MarshalJSONObject
+type JSONField struct {
+	Name  string
+	Value interface{}
+}
+
+// MarshalJSONObject encodes fields as a JSON object, keeping the order
+// of the fields
+func MarshalJSONObject(fields []JSONField) ([]byte, error) {
+	var buf bytes.Buffer
+	(&buf).WriteByte('{')
+	for i, field := range fields {
+		if i != 0 {
+			(&buf).WriteByte(',')
+		}
+		name, err := json.Marshal(field.Name)
+		if err != nil {
+			return nil, err
+		}
+		(&buf).Write(name)
+		(&buf).WriteByte(':')
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, fmt.Errorf("Failed encoding field %q:%v", field.Name, err)
+		}
+		(&buf).Write(value)
+	}
+	(&buf).WriteByte('}')
+	return (&buf).Bytes(), nil
+}
+
+// JSONNumber returns the value of a NUMERIC column as a dec.JSONNumber
+// so that MarshalJSONObject writes it as a JSON number instead of a
+// string. NULL is returned as nil and other values are unchanged.
+func JSONNumber(v interface{}) interface{} {
+	switch v := v.(type) {
+	case Numeric:
+		return dec.JSONNumber{Dec: v.Dec}
+	case *Numeric:
+		if v == nil {
+			return nil
+		}
+		return dec.JSONNumber{Dec: v.Dec}
+	case NullNumeric:
+		if !v.Valid {
+			return nil
+		}
+		return dec.JSONNumber{Dec: v.Numeric.Dec}
+	case *NullNumeric:
+		if v == nil || !v.Valid {
+			return nil
+		}
+		return dec.JSONNumber{Dec: v.Numeric.Dec}
+	}
+	return v
+}
+
+// UnmarshalJSONObject decodes a JSON object into a map of its members
+// so that each can be decoded with UnmarshalJSONField
+func UnmarshalJSONObject(data []byte) (map[string]json.RawMessage, error) {
+	var result map[string]json.RawMessage
+	err := json.Unmarshal(data, &result)
+	return result, err
+}
+
+// UnmarshalJSONField decodes the value of the named field into v
+func UnmarshalJSONField(name string, data json.RawMessage, v interface{}) error {
+	err := json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("Failed decoding field %q:%v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package sillyquill_rt
+
+import "encoding/json"
+import "testing"
+
+func TestJSONNumber(t *testing.T) {
+	var v Numeric
+	v.SetString("1.50")
+	fields := []JSONField{
+		{Name: "a", Value: JSONNumber(v)},
+		{Name: "b", Value: JSONNumber(&v)},
+		{Name: "c", Value: JSONNumber((*Numeric)(nil))},
+		{Name: "d", Value: JSONNumber(NullNumeric{Numeric: v, Valid: true})},
+		{Name: "e", Value: JSONNumber(NullNumeric{})},
+		{Name: "f", Value: JSONNumber("1.50")},
+		{Name: "g", Value: v},
+		{Name: "h", Value: JSONNumber(&NullNumeric{Numeric: v, Valid: true})},
+		{Name: "i", Value: JSONNumber(&NullNumeric{})},
+		{Name: "j", Value: JSONNumber((*NullNumeric)(nil))},
+	}
+	data, err := MarshalJSONObject(fields)
+	expected := `{"a":1.50,"b":1.50,"c":null,"d":1.50,"e":null,"f":"1.50","g":"1.50","h":1.50,"i":null,"j":null}`
+	if err != nil || string(data) != expected {
+		t.Errorf("got %s, %v; expected %s", data, err, expected)
+	}
+
+	var decoded struct{ A, D Numeric }
+	err = json.Unmarshal(data, &decoded)
+	if err != nil || decoded.A.String() != "1.50" || decoded.D.String() != "1.50" {
+		t.Errorf("got %v, %v", decoded, err)
+	}
+}
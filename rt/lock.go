@@ -0,0 +1,63 @@
+package sillyquill_rt
+
+import "fmt"
+
+// The strengths of a row lock
+const LockForUpdate = "UPDATE"
+const LockForNoKeyUpdate = "NO KEY UPDATE"
+const LockForShare = "SHARE"
+const LockForKeyShare = "KEY SHARE"
+
+// Lock describes the locking clause of a SELECT. The zero value
+// takes no locks.
+type Lock struct {
+	Strength   string
+	SkipLocked bool
+	NoWait     bool
+}
+
+func (this Lock) IsZero() bool {
+	return this == Lock{}
+}
+
+// Clause returns the locking clause with a leading space, or the
+// empty string for the zero value
+func (this Lock) Clause() (string, error) {
+	if this.IsZero() {
+		return "", nil
+	}
+	if this.Strength == "" {
+		return "", fmt.Errorf("SKIP LOCKED and NOWAIT require a lock such as FOR UPDATE")
+	}
+	if this.SkipLocked && this.NoWait {
+		return "", fmt.Errorf("SKIP LOCKED and NOWAIT can not be combined")
+	}
+	clause := " FOR " + this.Strength
+	if this.SkipLocked {
+		clause += " SKIP LOCKED"
+	}
+	if this.NoWait {
+		clause += " NOWAIT"
+	}
+	return clause, nil
+}
+
+type NotInTransactionError struct {
+	Executor interface{}
+}
+
+func (this NotInTransactionError) Error() string {
+	return fmt.Sprintf("Row locks require a *sql.Tx, got %T, begin one with its BeginTx method", this.Executor)
+}
+
+// RequireTx returns NotInTransactionError if db can begin a
+// transaction, such as a *sql.DB. Locks taken outside a transaction
+// are released as soon as the statement completes. A *sql.Conn is
+// rejected too, even if BEGIN was run on it, since there is no way
+// to tell if it is in a transaction.
+func RequireTx(db Executor) error {
+	if _, ok := db.(txBeginner); ok {
+		return NotInTransactionError{Executor: db}
+	}
+	return nil
+}
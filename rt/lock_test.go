@@ -0,0 +1,37 @@
+package sillyquill_rt
+
+import "database/sql"
+import "testing"
+
+func TestLockClause(t *testing.T) {
+	for i, test := range []struct {
+		lock   Lock
+		clause string // "" and an error if invalid
+		valid  bool
+	}{
+		{Lock{}, "", true},
+		{Lock{Strength: LockForUpdate}, " FOR UPDATE", true},
+		{Lock{Strength: LockForShare, SkipLocked: true}, " FOR SHARE SKIP LOCKED", true},
+		{Lock{Strength: LockForNoKeyUpdate, NoWait: true}, " FOR NO KEY UPDATE NOWAIT", true},
+		{Lock{Strength: LockForKeyShare}, " FOR KEY SHARE", true},
+		{Lock{SkipLocked: true}, "", false},
+		{Lock{Strength: LockForUpdate, SkipLocked: true, NoWait: true}, "", false},
+	} {
+		clause, err := test.lock.Clause()
+		if clause != test.clause || (err == nil) != test.valid {
+			t.Errorf("#%d Clause() got %q, %v; want %q", i, clause, err, test.clause)
+		}
+	}
+}
+
+func TestRequireTx(t *testing.T) {
+	if err := RequireTx(&sql.Tx{}); err != nil {
+		t.Errorf("*sql.Tx got %v", err)
+	}
+	for _, db := range []Executor{&sql.DB{}, &sql.Conn{}} {
+		err := RequireTx(db)
+		if v, ok := err.(NotInTransactionError); !ok || v.Executor != db {
+			t.Errorf("%T got %v; want NotInTransactionError", db, err)
+		}
+	}
+}
@@ -0,0 +1,444 @@
+package sillyquill_rt
+
+import "bytes"
+import "database/sql"
+import "database/sql/driver"
+import "encoding/json"
+import "fmt"
+import "time"
+
+// The Null types hold the value of a nullable column without a
+// pointer, Valid is false when the column is NULL. Each one scans
+// NULL from the database, writes NULL when not Valid and encodes
+// as JSON null when not Valid.
+
+func isJSONNull(data []byte) bool {
+	return bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
+
+func marshalNullJSON(valid bool, v interface{}) ([]byte, error) {
+	if !valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(v)
+}
+
+// NullNumeric is not comparable, it is also the type of nullable
+// NUMERIC columns that are pointer fields
+type NullNumeric struct {
+	Numeric Numeric
+	Valid   bool
+}
+
+func (this *NullNumeric) Scan(src interface{}) error {
+	if src == nil {
+		*this = NullNumeric{}
+		return nil
+	}
+	var v Numeric
+	err := v.Scan(src)
+	if err != nil {
+		return err
+	}
+	*this = NullNumeric{Numeric: v, Valid: true}
+	return nil
+}
+
+func (this NullNumeric) Value() (driver.Value, error) {
+	if !this.Valid {
+		return nil, nil
+	}
+	return this.Numeric.Value()
+}
+
+func (this NullNumeric) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(this.Valid, this.Numeric)
+}
+
+func (this *NullNumeric) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		*this = NullNumeric{}
+		return nil
+	}
+	var v Numeric
+	err := v.UnmarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	*this = NullNumeric{Numeric: v, Valid: true}
+	return nil
+}
+
+type NullTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+func (this *NullTime) Scan(src interface{}) error {
+	var v sql.NullTime
+	err := v.Scan(src)
+	if err != nil {
+		return err
+	}
+	*this = NullTime{Time: v.Time, Valid: v.Valid}
+	return nil
+}
+
+func (this NullTime) Value() (driver.Value, error) {
+	if !this.Valid {
+		return nil, nil
+	}
+	return this.Time, nil
+}
+
+func (this NullTime) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(this.Valid, this.Time)
+}
+
+func (this *NullTime) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		*this = NullTime{}
+		return nil
+	}
+	var v time.Time
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	*this = NullTime{Time: v, Valid: true}
+	return nil
+}
+
+type NullString struct {
+	String string
+	Valid  bool
+}
+
+func (this *NullString) Scan(src interface{}) error {
+	var v sql.NullString
+	err := v.Scan(src)
+	if err != nil {
+		return err
+	}
+	*this = NullString{String: v.String, Valid: v.Valid}
+	return nil
+}
+
+func (this NullString) Value() (driver.Value, error) {
+	if !this.Valid {
+		return nil, nil
+	}
+	return this.String, nil
+}
+
+func (this NullString) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(this.Valid, this.String)
+}
+
+func (this *NullString) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		*this = NullString{}
+		return nil
+	}
+	var v string
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	*this = NullString{String: v, Valid: true}
+	return nil
+}
+
+type NullBool struct {
+	Bool  bool
+	Valid bool
+}
+
+func (this *NullBool) Scan(src interface{}) error {
+	var v sql.NullBool
+	err := v.Scan(src)
+	if err != nil {
+		return err
+	}
+	*this = NullBool{Bool: v.Bool, Valid: v.Valid}
+	return nil
+}
+
+func (this NullBool) Value() (driver.Value, error) {
+	if !this.Valid {
+		return nil, nil
+	}
+	return this.Bool, nil
+}
+
+func (this NullBool) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(this.Valid, this.Bool)
+}
+
+func (this *NullBool) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		*this = NullBool{}
+		return nil
+	}
+	var v bool
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	*this = NullBool{Bool: v, Valid: true}
+	return nil
+}
+
+type NullInt16 struct {
+	Int16 int16
+	Valid bool
+}
+
+func (this *NullInt16) Scan(src interface{}) error {
+	var v sql.NullInt16
+	err := v.Scan(src)
+	if err != nil {
+		return err
+	}
+	*this = NullInt16{Int16: v.Int16, Valid: v.Valid}
+	return nil
+}
+
+func (this NullInt16) Value() (driver.Value, error) {
+	if !this.Valid {
+		return nil, nil
+	}
+	return int64(this.Int16), nil
+}
+
+func (this NullInt16) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(this.Valid, this.Int16)
+}
+
+func (this *NullInt16) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		*this = NullInt16{}
+		return nil
+	}
+	var v int16
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	*this = NullInt16{Int16: v, Valid: true}
+	return nil
+}
+
+type NullInt32 struct {
+	Int32 int32
+	Valid bool
+}
+
+func (this *NullInt32) Scan(src interface{}) error {
+	var v sql.NullInt32
+	err := v.Scan(src)
+	if err != nil {
+		return err
+	}
+	*this = NullInt32{Int32: v.Int32, Valid: v.Valid}
+	return nil
+}
+
+func (this NullInt32) Value() (driver.Value, error) {
+	if !this.Valid {
+		return nil, nil
+	}
+	return int64(this.Int32), nil
+}
+
+func (this NullInt32) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(this.Valid, this.Int32)
+}
+
+func (this *NullInt32) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		*this = NullInt32{}
+		return nil
+	}
+	var v int32
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	*this = NullInt32{Int32: v, Valid: true}
+	return nil
+}
+
+type NullInt64 struct {
+	Int64 int64
+	Valid bool
+}
+
+func (this *NullInt64) Scan(src interface{}) error {
+	var v sql.NullInt64
+	err := v.Scan(src)
+	if err != nil {
+		return err
+	}
+	*this = NullInt64{Int64: v.Int64, Valid: v.Valid}
+	return nil
+}
+
+func (this NullInt64) Value() (driver.Value, error) {
+	if !this.Valid {
+		return nil, nil
+	}
+	return this.Int64, nil
+}
+
+func (this NullInt64) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(this.Valid, this.Int64)
+}
+
+func (this *NullInt64) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		*this = NullInt64{}
+		return nil
+	}
+	var v int64
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	*this = NullInt64{Int64: v, Valid: true}
+	return nil
+}
+
+type NullFloat32 struct {
+	Float32 float32
+	Valid   bool
+}
+
+func (this *NullFloat32) Scan(src interface{}) error {
+	var v sql.NullFloat64
+	err := v.Scan(src)
+	if err != nil {
+		return err
+	}
+	*this = NullFloat32{Float32: float32(v.Float64), Valid: v.Valid}
+	return nil
+}
+
+func (this NullFloat32) Value() (driver.Value, error) {
+	if !this.Valid {
+		return nil, nil
+	}
+	return float64(this.Float32), nil
+}
+
+func (this NullFloat32) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(this.Valid, this.Float32)
+}
+
+func (this *NullFloat32) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		*this = NullFloat32{}
+		return nil
+	}
+	var v float32
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	*this = NullFloat32{Float32: v, Valid: true}
+	return nil
+}
+
+type NullFloat64 struct {
+	Float64 float64
+	Valid   bool
+}
+
+func (this *NullFloat64) Scan(src interface{}) error {
+	var v sql.NullFloat64
+	err := v.Scan(src)
+	if err != nil {
+		return err
+	}
+	*this = NullFloat64{Float64: v.Float64, Valid: v.Valid}
+	return nil
+}
+
+func (this NullFloat64) Value() (driver.Value, error) {
+	if !this.Valid {
+		return nil, nil
+	}
+	return this.Float64, nil
+}
+
+func (this NullFloat64) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(this.Valid, this.Float64)
+}
+
+func (this *NullFloat64) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		*this = NullFloat64{}
+		return nil
+	}
+	var v float64
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	*this = NullFloat64{Float64: v, Valid: true}
+	return nil
+}
+
+// NullBytes is not comparable, but unlike []byte an empty value
+// that is not NULL survives encoding
+type NullBytes struct {
+	Bytes []byte
+	Valid bool
+}
+
+func (this *NullBytes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*this = NullBytes{}
+	case []byte:
+		*this = NullBytes{Bytes: append([]byte{}, v...), Valid: true}
+	case string:
+		*this = NullBytes{Bytes: []byte(v), Valid: true}
+	default:
+		return fmt.Errorf("Value %v(%T) not convertible to bytes", src, src)
+	}
+	return nil
+}
+
+func (this NullBytes) Value() (driver.Value, error) {
+	if !this.Valid {
+		return nil, nil
+	}
+	return this.bytes(), nil
+}
+
+func (this NullBytes) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(this.Valid, this.bytes())
+}
+
+// bytes never returns nil, which would be written as NULL
+func (this NullBytes) bytes() []byte {
+	if this.Bytes == nil {
+		return []byte{}
+	}
+	return this.Bytes
+}
+
+func (this *NullBytes) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		*this = NullBytes{}
+		return nil
+	}
+	var v []byte
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	*this = NullBytes{Bytes: v, Valid: true}
+	return nil
+}
@@ -0,0 +1,75 @@
+package sillyquill_rt
+
+import "database/sql"
+import "database/sql/driver"
+import "encoding/json"
+import "reflect"
+import "testing"
+import "time"
+
+// nullValue is implemented by the pointer to each Null type
+type nullValue interface {
+	sql.Scanner
+	driver.Valuer
+	json.Marshaler
+	json.Unmarshaler
+}
+
+var when = time.Date(2020, 5, 1, 12, 30, 0, 0, time.UTC)
+
+var nullTests = []struct {
+	new   func() nullValue
+	src   interface{}  // scanned from the database
+	value driver.Value // written to the database
+	json  string
+}{
+	{func() nullValue { return new(NullNumeric) }, []byte("1.50"), "1.50", `"1.50"`},
+	{func() nullValue { return new(NullTime) }, when, when, `"2020-05-01T12:30:00Z"`},
+	{func() nullValue { return new(NullString) }, "a", "a", `"a"`},
+	{func() nullValue { return new(NullBool) }, true, true, `true`},
+	{func() nullValue { return new(NullInt16) }, int64(-7), int64(-7), `-7`},
+	{func() nullValue { return new(NullInt32) }, int64(7), int64(7), `7`},
+	{func() nullValue { return new(NullInt64) }, int64(1) << 40, int64(1) << 40, `1099511627776`},
+	{func() nullValue { return new(NullFloat32) }, float64(1.5), float64(1.5), `1.5`},
+	{func() nullValue { return new(NullFloat64) }, float64(-2.25), float64(-2.25), `-2.25`},
+	{func() nullValue { return new(NullBytes) }, []byte{}, []byte{}, `""`},
+	{func() nullValue { return new(NullBytes) }, []byte{1, 2}, []byte{1, 2}, `"AQI="`},
+}
+
+func TestNullTypes(t *testing.T) {
+	for i, test := range nullTests {
+		v := test.new()
+		if value, err := v.Value(); value != nil || err != nil {
+			t.Errorf("#%d zero %T Value() got %v, %v; want nil", i, v, value, err)
+		}
+		if data, err := v.MarshalJSON(); string(data) != "null" || err != nil {
+			t.Errorf("#%d zero %T MarshalJSON() got %s, %v; want null", i, v, data, err)
+		}
+
+		if err := v.Scan(test.src); err != nil {
+			t.Fatalf("#%d %T Scan(%v) got %v", i, v, test.src, err)
+		}
+		if value, err := v.Value(); !reflect.DeepEqual(value, test.value) || err != nil {
+			t.Errorf("#%d %T Value() got %#v, %v; want %#v", i, v, value, err, test.value)
+		}
+		data, err := v.MarshalJSON()
+		if string(data) != test.json || err != nil {
+			t.Errorf("#%d %T MarshalJSON() got %s, %v; want %s", i, v, data, err, test.json)
+		}
+		w := test.new()
+		if err := w.UnmarshalJSON(data); err != nil || !reflect.DeepEqual(w, v) {
+			t.Errorf("#%d %T UnmarshalJSON(%s) got %+v, %v; want %+v", i, v, data, w, err, v)
+		}
+
+		//NULL replaces a value that is set
+		if err := v.Scan(nil); err != nil {
+			t.Errorf("#%d %T Scan(nil) got %v", i, v, err)
+		}
+		if value, err := v.Value(); value != nil || err != nil {
+			t.Errorf("#%d %T Value() after Scan(nil) got %v, %v; want nil", i, v, value, err)
+		}
+		if err := w.UnmarshalJSON([]byte("null")); err != nil || !reflect.DeepEqual(w, test.new()) {
+			t.Errorf("#%d %T UnmarshalJSON(null) got %+v, %v", i, v, w, err)
+		}
+	}
+}
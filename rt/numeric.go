@@ -3,20 +3,25 @@ package sillyquill_rt
 import "github.com/hydrogen18/sillyquill/dec"
 import "fmt"
 import "database/sql/driver"
+import "math/big"
 
+// Numeric is the type of a NUMERIC column. The methods of dec.Dec are
+// promoted, including the text, JSON and binary marshalers, so a Numeric
+// is written to JSON as a string. JSONNumber writes it as a number.
 type Numeric struct {
 	dec.Dec
 }
 
+// Scan reads the text format of NUMERIC. The binary format can be
+// read with SetPgNumeric.
 func (this *Numeric) Scan(src interface{}) error {
-	if v, ok := src.(string); ok {
-		if _, ok = this.SetString(v); ok {
+	switch v := src.(type) {
+	case []byte:
+		if _, ok := this.SetStringBytes(v); ok {
 			return nil
 		}
-	}
-
-	if v, ok := src.([]uint8); ok {
-		if _, ok = this.SetString(string(v)); ok {
+	case string:
+		if _, ok := this.SetString(v); ok {
 			return nil
 		}
 	}
@@ -24,22 +29,56 @@ func (this *Numeric) Scan(src interface{}) error {
 }
 
 func (this Numeric) Value() (driver.Value, error) {
-
-	return this.String(), nil
+	return string(this.AppendString(nil)), nil
 }
 
-type NullNumeric Numeric
+// NumericOverflowError is returned by FitNumeric for a value that
+// does not fit in a numeric(Precision, Scale) column
+type NumericOverflowError struct {
+	Column    string
+	Value     string
+	Precision int
+	Scale     int
+}
 
-func (this NullNumeric) Value() (driver.Value, error) {
-	return Numeric(this).Value()
+func (this NumericOverflowError) Error() string {
+	return fmt.Sprintf("Value %s of column %q does not fit numeric(%d,%d)",
+		this.Value,
+		this.Column,
+		this.Precision,
+		this.Scale)
 }
 
-func (this *NullNumeric) Scan(src interface{}) error {
-	var v Numeric
-	err := (v).Scan(src)
-	if err != nil {
-		return err
+// FitNumeric rounds v to scale using r and checks that the result
+// has no more than precision digits, as PostgreSQL does for a
+// numeric(precision, scale) column. If r returns nil, as RoundExact
+// does for a value that needs rounding, or the result has too many
+// digits v is unchanged and NumericOverflowError is returned. NaN
+// fits any column but an infinity does not.
+func FitNumeric(column string, v *Numeric, precision, scale int, r dec.Rounder) error {
+	overflow := NumericOverflowError{
+		Column:    column,
+		Value:     v.String(),
+		Precision: precision,
+		Scale:     scale,
+	}
+	if v.IsNaN() {
+		return nil
+	}
+	if v.IsInf() {
+		return overflow
+	}
+	var rounded dec.Dec
+	if rounded.Round(&v.Dec, dec.Scale(scale), r) == nil {
+		return overflow
+	}
+	var limit big.Int
+	limit.Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+	if new(big.Int).Abs(rounded.Unscaled()).Cmp(&limit) >= 0 {
+		return overflow
 	}
-	this.Dec = v.Dec
+	//Set would reuse the digits of v, which can be shared
+	//with the value passed to a setter
+	v.Dec = rounded
 	return nil
 }
@@ -0,0 +1,42 @@
+package sillyquill_rt
+
+import "testing"
+import "github.com/hydrogen18/sillyquill/dec"
+
+func numeric(s string) Numeric {
+	var v Numeric
+	v.SetString(s)
+	return v
+}
+
+func TestFitNumeric(t *testing.T) {
+	for i, test := range []struct {
+		in               string
+		precision, scale int
+		r                dec.Rounder
+		out              string // "" if it does not fit
+	}{
+		{"19.995", 8, 2, dec.RoundHalfEven, "20.00"},
+		{"19.985", 8, 2, dec.RoundHalfEven, "19.98"},
+		{"19.985", 8, 2, dec.RoundHalfUp, "19.99"},
+		{"-1.5", 4, 3, dec.RoundHalfEven, "-1.500"},
+		{"12.5", 4, 3, dec.RoundHalfEven, ""},
+		{"9.9995", 4, 3, dec.RoundHalfEven, ""},
+		{"1.234", 8, 2, dec.RoundExact, ""},
+		{"1.2", 8, 2, dec.RoundExact, "1.20"},
+		{"NaN", 4, 2, dec.RoundHalfEven, "NaN"},
+		{"Infinity", 4, 2, dec.RoundHalfEven, ""},
+	} {
+		v := numeric(test.in)
+		err := FitNumeric("amount", &v, test.precision, test.scale, test.r)
+		switch {
+		case test.out == "":
+			overflow, ok := err.(NumericOverflowError)
+			if !ok || overflow.Column != "amount" || overflow.Value != test.in || v.String() != test.in {
+				t.Errorf("#%d FitNumeric(%s) got %s, %v; want NumericOverflowError", i, test.in, v.String(), err)
+			}
+		case err != nil || v.String() != test.out:
+			t.Errorf("#%d FitNumeric(%s) got %s, %v; want %s", i, test.in, v.String(), err, test.out)
+		}
+	}
+}
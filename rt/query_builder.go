@@ -56,3 +56,34 @@ func BuildAndEqualClause(
 	w.Truncate(w.Len() - len(and))
 
 }
+
+// BuildKeysetClause writes a comparison selecting rows whose columns
+// sort after the parameters, such as ("a","b") > ($1,$2)
+func BuildKeysetClause(
+	w *bytes.Buffer,
+	parameterIndex int,
+	columns []string) {
+
+	w.WriteString("(")
+	for _, v := range columns {
+		fmt.Fprintf(w, "%q,", v)
+	}
+	w.Truncate(w.Len() - 1)
+	w.WriteString(") > (")
+	for i := range columns {
+		fmt.Fprintf(w, "$%d,", i+parameterIndex)
+	}
+	w.Truncate(w.Len() - 1)
+	w.WriteString(")")
+}
+
+func BuildOrderByClause(
+	w *bytes.Buffer,
+	columns []string) {
+
+	w.WriteString(" ORDER BY ")
+	for _, v := range columns {
+		fmt.Fprintf(w, "%q,", v)
+	}
+	w.Truncate(w.Len() - 1)
+}
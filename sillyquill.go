@@ -1,14 +1,29 @@
 package main
 
+import "context"
 import "flag"
+import "fmt"
+import "os"
 import "github.com/spiceworks/spicelog"
 import _ "github.com/lib/pq"
 import "database/sql"
-import "sync"
+import "strings"
+import "github.com/hydrogen18/sillyquill/gen"
 import "github.com/BurntSushi/toml"
 
+type column struct {
+	Tags map[string]string `toml:"tags"`
+}
+
 type table struct {
-	Exclude bool `toml:"exclude"`
+	Exclude  bool              `toml:"exclude"`
+	Emitters []string          `toml:"emitters"`
+	Columns  map[string]column `toml:"columns"`
+	Audit    bool              `toml:"audit"`
+}
+
+type plugin struct {
+	Command []string `toml:"command"`
 }
 
 type config struct {
@@ -19,10 +34,41 @@ type config struct {
 	ConnectionMax int              `toml:"connection-max"`
 	Tables        map[string]table `toml:"tables"`
 	TableMode   string `toml:"table-mode"`
+	SchemaAdapter string `toml:"schema-adapter"`
+	SchemaFile    string `toml:"schema-file"`
+	TemplateDir   string `toml:"template-dir"`
+	Emitters      []string          `toml:"emitters"`
+	Plugins       map[string]plugin `toml:"plugins"`
+	Tags          map[string]string `toml:"tags"`
+	JSONNulls     bool              `toml:"json-nulls"`
+	JSONNumbers   bool              `toml:"json-numbers"`
+	AuditTable    string            `toml:"audit-table"`
+	NullableFields string           `toml:"nullable-fields"`
+	NumericRounder string           `toml:"numeric-rounder"`
+}
+
+func openDatabase(conf config) *sql.DB {
+	db, err := sql.Open("postgres", conf.DB)
+	if err != nil {
+		spicelog.Fatalf("Failed opening database:%v", err)
+	}
+	db.SetMaxOpenConns(conf.ConnectionMax)
+
+	err = db.Ping()
+	if err != nil {
+		spicelog.Fatalf("Database unreachable:%v", err)
+	}
+	return db
 }
 
 func main() {
 	tomlFile := flag.String("conf", "sillyquill.toml", "TOML configuration file path")
+	check := flag.Bool("check", false, "Verify the files in output-dir are up to date instead of writing them")
+	keepGoing := flag.Bool("keep-going", false, "Process every table even after a table fails")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] [snapshot [file]]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 	defer spicelog.Stop()
 
@@ -45,6 +91,10 @@ func main() {
 	    conf.TableMode = "normal"
 	}
 	
+	if conf.SchemaAdapter == "" {
+		conf.SchemaAdapter = "information_schema"
+	}
+
 	var explicit bool
 	
 	if conf.TableMode == "explicit" {
@@ -52,61 +102,161 @@ func main() {
 		spicelog.Infof("The selected table mode is explicit. Only generating models for the tables listed in the configuration file.")
 	}
 
-	db, err := sql.Open("postgres", conf.DB)
-	if err != nil {
-		spicelog.Fatalf("Failed opening database:%v", err)
+	var adapter sillyquill_gen.SchemaAdapter
+	switch conf.SchemaAdapter {
+	case "information_schema":
+		db := openDatabase(conf)
+		defer db.Close()
+		adapter = sillyquill_gen.NewInformationSchemaAdapter(db, conf.Schema)
+	case "pg_catalog":
+		db := openDatabase(conf)
+		defer db.Close()
+		adapter = sillyquill_gen.NewPgCatalogAdapter(db, conf.Schema)
+	case "ddl":
+		adapter = &sillyquill_gen.DDLAdapter{
+			File:        conf.SchemaFile,
+			TableSchema: conf.Schema,
+		}
+	case "snapshot":
+		adapter = &sillyquill_gen.SnapshotAdapter{
+			File: conf.SchemaFile,
+		}
+	default:
+		spicelog.Fatalf("Unknown schema adapter %q", conf.SchemaAdapter)
 	}
-	defer db.Close()
-	db.SetMaxOpenConns(conf.ConnectionMax)
+	spicelog.Infof("Querying schema %q using %s", conf.Schema, conf.SchemaAdapter)
 
-	err = db.Ping()
-	if err != nil {
-		spicelog.Fatalf("Database unreachable:%v", err)
+	if flag.Arg(0) == "snapshot" {
+		tables, err := adapter.Tables()
+		if err != nil {
+			spicelog.Fatalf("Failed querying for tables:%v", err)
+		}
+		snapshot, err := sillyquill_gen.NewSnapshot(conf.Schema, tables)
+		if err != nil {
+			spicelog.Fatalf("Failed creating snapshot:%v", err)
+		}
+		err = snapshot.WriteFile(flag.Arg(1))
+		if err != nil {
+			spicelog.Fatalf("Failed writing snapshot:%v", err)
+		}
+		spicelog.Infof("Wrote snapshot of %d tables", len(snapshot.Tables))
+		return
 	}
 
-	adapter := &InformationSchemaAdapter{
-		db:          db,
-		TableSchema: conf.Schema,
+	switch conf.NullableFields {
+	case "", "pointer", "value":
+	default:
+		spicelog.Fatalf("Unknown nullable-fields %q, expected \"pointer\" or \"value\"", conf.NullableFields)
 	}
-	spicelog.Infof("Querying schema %q", conf.Schema)
-	tables, err := adapter.Tables()
-	if err != nil {
-		spicelog.Fatalf("Failed querying for tables:%v", err)
+
+	for name, pluginConf := range conf.Plugins {
+		factory := sillyquill_gen.NewPluginEmitterFactory(name, pluginConf.Command, conf.Package)
+		err = sillyquill_gen.DefaultEmitters.Register(name, factory)
+		if err != nil {
+			spicelog.Fatalf("Failed registering plugin:%v", err)
+		}
 	}
 
-	wg := new(sync.WaitGroup)
-	for _, table := range tables {
-		spicelog.Infof("Processing table %q", table.Name())
+	enabled := append([]string{}, conf.Emitters...)
+	for _, tableConf := range conf.Tables {
+		enabled = append(enabled, tableConf.Emitters...)
+	}
+	for _, name := range enabled {
+		if _, ok := sillyquill_gen.DefaultEmitters.Lookup(name); !ok {
+			spicelog.Fatalf("Unknown emitter %q, registered emitters are %v",
+				name,
+				sillyquill_gen.DefaultEmitters.Names())
+		}
+	}
 
-		tableConf, ok := conf.Tables[table.Name()]
-		if ok {			
-			if tableConf.Exclude {
-				spicelog.Infof("Skipping table %q", table.Name())
-				continue
-			}
-		} else {
-			if explicit {
-				spicelog.Infof("Skipping table %q", table.Name())
-				continue
+	structTags := sillyquill_gen.StructTags{
+		Styles:    conf.Tags,
+		Overrides: make(map[string]map[string]map[string]string),
+	}
+	for tableName, tableConf := range conf.Tables {
+		for columnName, columnConf := range tableConf.Columns {
+			if structTags.Overrides[tableName] == nil {
+				structTags.Overrides[tableName] = make(map[string]map[string]string)
 			}
+			structTags.Overrides[tableName][columnName] = columnConf.Tags
 		}
+	}
 
-		wg.Add(1)
-		go func(t Table) {
-			defer wg.Done()
-
-			me := NewModelEmitter()
-			me.Package = conf.Package
+	opts := sillyquill_gen.Options{
+		Source:      adapter,
+		Package:     conf.Package,
+		Concurrency: conf.ConnectionMax,
+		KeepGoing:   *keepGoing,
+		TemplateDir: conf.TemplateDir,
+		StructTags:  structTags,
+		JSONNulls:   conf.JSONNulls,
+		JSONNumbers: conf.JSONNumbers,
+		AuditTable:  conf.AuditTable,
+		NullValueTypes: conf.NullableFields == "value",
+		NumericRounder: conf.NumericRounder,
+		Audit: func(tableName string) bool {
+			return conf.Tables[tableName].Audit
+		},
+		Include: func(tableName string) bool {
+			tableConf, ok := conf.Tables[tableName]
+			if ok {
+				return !tableConf.Exclude
+			}
+			return !explicit
+		},
+		Emitters: func(tableName string) []string {
+			emitters := append([]string{}, conf.Emitters...)
+			for _, name := range conf.Tables[tableName].Emitters {
+				found := false
+				for _, v := range emitters {
+					if v == name {
+						found = true
+					}
+				}
+				if !found {
+					emitters = append(emitters, name)
+				}
+			}
+			return emitters
+		},
+	}
 
-			err = me.Emit(t, conf.OutputDir)
-			if err != nil {
-				spicelog.Errorf("Error processing table %q:%v", t.Name(), err)
-			} else {
-        spicelog.Infof("Emitted model for table %q", t.Name())
-      }
+	generated := make(sillyquill_gen.MemorySink)
+	if *check {
+		opts.Sink = generated
+	} else {
+		opts.Sink = sillyquill_gen.DirSink{Dir: conf.OutputDir}
+	}
 
-		}(table)
+	result, err := sillyquill_gen.Generate(context.Background(), opts)
+	if genErr, ok := err.(sillyquill_gen.GenerateError); ok {
+		var names []string
+		for _, v := range genErr.Errors {
+			names = append(names, v.TableName)
+		}
+		spicelog.Errorf("Failed processing %d of %d tables:%s",
+			len(genErr.Errors),
+			result.Selected,
+			strings.Join(names, ", "))
+		if !*keepGoing {
+			spicelog.Errorf("Stopped after the first error, use -keep-going to process every table")
+		}
+		spicelog.Stop()
+		os.Exit(1)
+	} else if err != nil {
+		spicelog.Fatalf("Failed generating code:%v", err)
 	}
-	wg.Wait()
 
+	if *check {
+		upToDate, err := sillyquill_gen.CheckGenerated(conf.OutputDir, generated, os.Stdout)
+		if err != nil {
+			spicelog.Fatalf("Failed checking %q:%v", conf.OutputDir, err)
+		}
+		if !upToDate {
+			spicelog.Errorf("Generated code in %q is out of date", conf.OutputDir)
+			spicelog.Stop()
+			os.Exit(1)
+		}
+		spicelog.Infof("Generated code in %q is up to date", conf.OutputDir)
+	}
 }